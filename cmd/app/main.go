@@ -2,6 +2,8 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -24,6 +26,9 @@ func main() {
 	flag.Parse()
 
 	conf := config.New(configFile)
+	if err := app.ValidateConfig(conf); err != nil {
+		log.Fatal(fmt.Errorf("main validate config: %w", err))
+	}
 	logg := logger.New(conf.Log.Level)
 
 	previewer := service.New(logg)
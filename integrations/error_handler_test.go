@@ -89,7 +89,7 @@ func (s *ErrorHandleSuite) TestWrongWidth() {
 }
 
 func (s *ErrorHandleSuite) TestWrongHeight() {
-	height := "0"
+	height := "-1"
 	req, err := http.NewRequestWithContext(
 		s.ctx,
 		http.MethodGet,
@@ -180,6 +180,186 @@ func (s *ErrorHandleSuite) TestLoadingError() {
 	s.Require().True(is)
 }
 
+func (s *ErrorHandleSuite) TestWrongGravity() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?gravity=middle", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong gravity: middle")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongFocalPoint() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?fp=1.3,0.7", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong focal point: 1.3,0.7")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestBothSizesAuto() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/0/auto/%s/gopher_333x666.jpg", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong size: width and height are both auto")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongFormat() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?format=ico", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong format: ico")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongQuality() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?quality=150", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong quality: 150")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongFilter() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?filter=bicubic", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong filter: bicubic")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongOperation() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/ops/resize:300/%s/gopher_333x666.jpg", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong operation arguments: resize:300")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestUnknownPreset() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/p/avatar_large/%s/gopher_333x666.jpg", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "unknown preset: avatar_large")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongAdjustment() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?brightness=150", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong brightness: 150")
+	s.Require().True(is)
+}
+
+func (s *ErrorHandleSuite) TestWrongWatermark() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/500/500/%s/gopher_333x666.jpg?watermark=smart", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+	respBody, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusUnprocessableEntity, response.StatusCode)
+	is := strings.Contains(string(respBody), "wrong watermark: smart")
+	s.Require().True(is)
+}
+
 func TestErrorHandleSuite(t *testing.T) {
 	suite.Run(t, NewErrorHandleSuite())
 }
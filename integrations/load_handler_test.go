@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/disintegration/imaging"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -65,9 +66,10 @@ func (s *LoadHandleSuite) TestLoadingImage() {
 	s.Require().True(is)
 
 	s.Require().Equal(http.StatusOK, response.StatusCode)
-	respBody, err := io.ReadAll(response.Body)
+	img, err := imaging.Decode(response.Body)
 	s.Require().NoError(err)
-	s.Require().Len(respBody, 24426)
+	s.Require().Equal(s.width, strconv.Itoa(img.Bounds().Dx()))
+	s.Require().Equal(s.height, strconv.Itoa(img.Bounds().Dy()))
 }
 
 func (s *LoadHandleSuite) TestLoadingImageCached() {
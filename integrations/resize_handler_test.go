@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"image"
+	"image/gif"
 	"io"
 	"net"
 	"net/http"
@@ -89,6 +91,571 @@ func (s *ResizeHandleSuite) TestResizingImage() {
 	}
 }
 
+func (s *ResizeHandleSuite) TestFittingImage() {
+	for _, test := range []struct {
+		width        string
+		height       string
+		url          string
+		resultWidth  string
+		resultHeight string
+	}{
+		{width: "200", height: "300", url: "gopher_333x666.jpg", resultWidth: "150", resultHeight: "300"},
+		{width: "500", height: "500", url: "gopher_2000x1000.jpg", resultWidth: "500", resultHeight: "250"},
+		{width: "300", height: "300", url: "sea_632x474.jpg", resultWidth: "300", resultHeight: "225"},
+		{width: "300", height: "300", url: "ubuntu_989x587.png", resultWidth: "300", resultHeight: "178"},
+	} {
+		s.Run(fmt.Sprintf("fit width:%s height:%s url: %s", test.width, test.height, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fit/%s/%s/%s/%s", s.addr, test.width, test.height, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+
+			bounds := img.Bounds()
+			s.Require().Equal(test.resultWidth, strconv.Itoa(bounds.Dx()))
+			s.Require().Equal(test.resultHeight, strconv.Itoa(bounds.Dy()))
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestFillingImageWithGravity() {
+	for _, gravity := range []string{"top", "bottom-right", "left", "smart"} {
+		s.Run(fmt.Sprintf("fill gravity: %s", gravity), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/150/%s/gopher_333x666.jpg?gravity=%s", s.addr, nginxHost, gravity),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(300, img.Bounds().Dx())
+			s.Require().Equal(150, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestFillingImageWithFocalPoint() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/300/100/%s/gopher_333x666.jpg?fp=0.5,0.2", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	s.Require().Equal(http.StatusOK, response.StatusCode)
+	img, err := imaging.Decode(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(300, img.Bounds().Dx())
+	s.Require().Equal(100, img.Bounds().Dy())
+}
+
+func (s *ResizeHandleSuite) TestResizingImageWithAutoSize() {
+	for _, test := range []struct {
+		width        string
+		height       string
+		url          string
+		resultWidth  int
+		resultHeight int
+	}{
+		{width: "300", height: "0", url: "gopher_2000x1000.jpg", resultWidth: 300, resultHeight: 150},
+		{width: "auto", height: "333", url: "gopher_333x666.jpg", resultWidth: 167, resultHeight: 333},
+	} {
+		s.Run(fmt.Sprintf("auto width:%s height:%s url: %s", test.width, test.height, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/%s/%s/%s/%s", s.addr, test.width, test.height, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestPaddingImage() {
+	for _, test := range []struct {
+		width  string
+		height string
+		url    string
+	}{
+		{width: "300", height: "300", url: fmt.Sprintf("%s/gopher_333x666.jpg?bg=000000", nginxHost)},
+		{width: "500", height: "200", url: fmt.Sprintf("%s/sea_632x474.jpg", nginxHost)},
+		{width: "300", height: "300", url: fmt.Sprintf("%s/ubuntu_989x587.png?bg=transparent", nginxHost)},
+	} {
+		s.Run(fmt.Sprintf("pad width:%s height:%s url: %s", test.width, test.height, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/pad/%s/%s/%s", s.addr, test.width, test.height, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.width, strconv.Itoa(img.Bounds().Dx()))
+			s.Require().Equal(test.height, strconv.Itoa(img.Bounds().Dy()))
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestResizingSmallImage() {
+	for _, test := range []struct {
+		mode         string
+		width        string
+		height       string
+		url          string
+		resultWidth  int
+		resultHeight int
+	}{
+		{mode: "fit", width: "1000", height: "1000", url: "gopher_333x666.jpg",
+			resultWidth: 500, resultHeight: 1000},
+		{mode: "fit", width: "1000", height: "1000", url: "gopher_333x666.jpg?filter=nearest",
+			resultWidth: 500, resultHeight: 1000},
+		{mode: "fit", width: "1000", height: "1000", url: "gopher_333x666.jpg?enlarge=false",
+			resultWidth: 333, resultHeight: 666},
+		{mode: "fill", width: "1000", height: "1000", url: "sea_632x474.jpg?enlarge=false",
+			resultWidth: 474, resultHeight: 474},
+		{mode: "fill", width: "1000", height: "0", url: "sea_632x474.jpg?enlarge=false",
+			resultWidth: 632, resultHeight: 474},
+		{mode: "pad", width: "1000", height: "1000", url: "sea_632x474.jpg?enlarge=false",
+			resultWidth: 1000, resultHeight: 1000},
+	} {
+		s.Run(fmt.Sprintf("%s width:%s height:%s url: %s", test.mode, test.width, test.height, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/%s/%s/%s/%s/%s", s.addr, test.mode, test.width, test.height, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestConvertingImageFormat() {
+	for format, contentType := range map[string]string{
+		"jpeg": "image/jpeg",
+		"png":  "image/png",
+		"gif":  "image/gif",
+		"bmp":  "image/bmp",
+		"tiff": "image/tiff",
+	} {
+		s.Run(fmt.Sprintf("format: %s", format), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/200/100/%s/ubuntu_989x587.png?format=%s", s.addr, nginxHost, format),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			s.Require().Equal(contentType, response.Header.Get("Content-Type"))
+			_, decoded, err := image.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(format, decoded)
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestNegotiatingImageFormat() {
+	for accept, contentType := range map[string]string{
+		"image/png,image/*;q=0.8":   "image/png",
+		"image/webp,image/*;q=0.8":  "image/jpeg",
+		"image/gif;q=0.9,image/bmp": "image/bmp",
+	} {
+		s.Run(fmt.Sprintf("accept: %s", accept), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/200/100/%s/sea_632x474.jpg?format=auto", s.addr, nginxHost),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			req.Header.Set("Accept", accept)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			s.Require().Equal(contentType, response.Header.Get("Content-Type"))
+			s.Require().Equal("Accept", response.Header.Get("Vary"))
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestOperationsChain() {
+	for _, test := range []struct {
+		ops          string
+		url          string
+		resultWidth  int
+		resultHeight int
+	}{
+		{ops: "resize:300x200/blur:2/grayscale", url: "gopher_333x666.jpg", resultWidth: 300, resultHeight: 200},
+		{ops: "fit:500x500/crop:200x100", url: "gopher_2000x1000.jpg", resultWidth: 200, resultHeight: 100},
+		{ops: "resize:0x100/sharpen:1", url: "ubuntu_989x587.png", resultWidth: 168, resultHeight: 100},
+		{ops: "trim:20/fill:100x100", url: "ubuntu_989x587.png", resultWidth: 100, resultHeight: 100},
+	} {
+		s.Run(fmt.Sprintf("ops: %s url: %s", test.ops, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/ops/%s/%s/%s", s.addr, test.ops, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestPresets() {
+	for _, test := range []struct {
+		preset       string
+		url          string
+		resultWidth  int
+		resultHeight int
+	}{
+		{preset: "avatar_small", url: "gopher_333x666.jpg", resultWidth: 64, resultHeight: 64},
+		{preset: "product_tile", url: "sea_632x474.jpg", resultWidth: 300, resultHeight: 300},
+		{preset: "archived_thumb", url: "gopher_2000x1000.jpg", resultWidth: 200, resultHeight: 100},
+		{preset: "partner_preview", url: "gopher_2000x1000.jpg", resultWidth: 400, resultHeight: 200},
+	} {
+		s.Run(fmt.Sprintf("preset: %s url: %s", test.preset, test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/p/%s/%s/%s", s.addr, test.preset, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestAdjustingImage() {
+	for _, query := range []string{
+		"blur=5",
+		"sharpen=1.5",
+		"brightness=-20&contrast=30",
+		"gamma=0.7&saturation=150",
+		"effect=grayscale",
+		"effect=sepia,invert",
+	} {
+		s.Run(fmt.Sprintf("adjustments: %s", query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/200/%s/sea_632x474.jpg?%s", s.addr, nginxHost, query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(300, img.Bounds().Dx())
+			s.Require().Equal(200, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestRotatingImage() {
+	for _, test := range []struct {
+		query        string
+		resultWidth  int
+		resultHeight int
+	}{
+		{query: "rotate=90", resultWidth: 666, resultHeight: 333},
+		{query: "rotate=180&flip=h", resultWidth: 167, resultHeight: 333},
+		{query: "rotate=270&flip=v", resultWidth: 666, resultHeight: 333},
+	} {
+		s.Run(fmt.Sprintf("orientation: %s", test.query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/0/333/%s/gopher_333x666.jpg?%s", s.addr, nginxHost, test.query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestWatermarkingImage() {
+	for _, query := range []string{
+		"watermark=true",
+		"watermark=top-left",
+		"watermark=center",
+	} {
+		s.Run(fmt.Sprintf("watermark: %s", query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/200/%s/sea_632x474.jpg?%s", s.addr, nginxHost, query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(300, img.Bounds().Dx())
+			s.Require().Equal(200, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestCaptioningImage() {
+	for _, query := range []string{
+		"text=SOLD",
+		"text=%E2%82%AC+19.99&text_size=32&text_color=000&text_bg=ffcc00&text_position=top-right",
+	} {
+		s.Run(fmt.Sprintf("caption: %s", query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/200/%s/sea_632x474.jpg?%s", s.addr, nginxHost, query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(300, img.Bounds().Dx())
+			s.Require().Equal(200, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestMaskingImage() {
+	for _, test := range []struct {
+		query        string
+		resultWidth  int
+		resultHeight int
+	}{
+		{query: "radius=20", resultWidth: 300, resultHeight: 200},
+		{query: "mask=circle", resultWidth: 200, resultHeight: 200},
+		{query: "mask=circle&border=3", resultWidth: 206, resultHeight: 206},
+		{query: "radius=20&border=3", resultWidth: 306, resultHeight: 206},
+	} {
+		s.Run(fmt.Sprintf("mask: %s", test.query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/200/%s/sea_632x474.jpg?%s", s.addr, nginxHost, test.query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			s.Require().Equal("image/png", response.Header.Get("Content-Type"))
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+			s.Require().Equal(uint32(0), alphaAt(img, 0, 0))
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestDecoratingImage() {
+	for _, test := range []struct {
+		query        string
+		resultWidth  int
+		resultHeight int
+	}{
+		{query: "border=5&border_color=336699", resultWidth: 310, resultHeight: 210},
+		{query: "shadow=4", resultWidth: 324, resultHeight: 224},
+		{query: "border=2&shadow=2", resultWidth: 316, resultHeight: 216},
+	} {
+		s.Run(fmt.Sprintf("decoration: %s", test.query), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/300/200/%s/sea_632x474.jpg?%s", s.addr, nginxHost, test.query),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(test.resultWidth, img.Bounds().Dx())
+			s.Require().Equal(test.resultHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestDecodingSourceFormats() {
+	for _, test := range []struct {
+		url         string
+		contentType string
+	}{
+		{url: "tux_386x395.webp", contentType: "image/png"},
+		{url: "video_150x103.tiff", contentType: "image/tiff"},
+		{url: "colormap_150x103.bmp", contentType: "image/bmp"},
+	} {
+		s.Run(fmt.Sprintf("source: %s", test.url), func() {
+			req, err := http.NewRequestWithContext(
+				s.ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s/fill/100/100/%s/%s", s.addr, nginxHost, test.url),
+				bytes.NewReader(nil),
+			)
+			s.Require().NoError(err)
+			response, err := s.client.Do(req)
+			s.Require().NoError(err)
+			defer response.Body.Close()
+
+			s.Require().Equal(http.StatusOK, response.StatusCode)
+			s.Require().Equal(test.contentType, response.Header.Get("Content-Type"))
+			img, err := imaging.Decode(response.Body)
+			s.Require().NoError(err)
+			s.Require().Equal(100, img.Bounds().Dx())
+			s.Require().Equal(100, img.Bounds().Dy())
+		})
+	}
+}
+
+func (s *ResizeHandleSuite) TestRasterizingSVG() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/96/96/%s/icon_24x24.svg", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	s.Require().Equal(http.StatusOK, response.StatusCode)
+	s.Require().Equal("image/png", response.Header.Get("Content-Type"))
+	img, err := imaging.Decode(response.Body)
+	s.Require().NoError(err)
+	s.Require().Equal(96, img.Bounds().Dx())
+	s.Require().Equal(96, img.Bounds().Dy())
+	s.Require().Equal(uint32(0), alphaAt(img, 0, 0))
+	s.Require().Equal(uint32(0xffff), alphaAt(img, 48, 10))
+}
+
+func (s *ResizeHandleSuite) TestResizingAnimation() {
+	req, err := http.NewRequestWithContext(
+		s.ctx,
+		http.MethodGet,
+		fmt.Sprintf("http://%s/fill/100/50/%s/spinner_200x200.gif", s.addr, nginxHost),
+		bytes.NewReader(nil),
+	)
+	s.Require().NoError(err)
+	response, err := s.client.Do(req)
+	s.Require().NoError(err)
+	defer response.Body.Close()
+
+	s.Require().Equal(http.StatusOK, response.StatusCode)
+	animation, err := gif.DecodeAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().Len(animation.Image, 8)
+	s.Require().Equal(10, animation.Delay[7])
+	for _, img := range animation.Image {
+		s.Require().Equal(100, img.Bounds().Dx())
+		s.Require().Equal(50, img.Bounds().Dy())
+	}
+}
+
+func alphaAt(img image.Image, x, y int) uint32 {
+	_, _, _, a := img.At(x, y).RGBA()
+	return a
+}
+
 func TestResizeHandleSuite(t *testing.T) {
 	suite.Run(t, NewResizeHandleSuite())
 }
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/AndreiGoStorm/previewer/internal/service"
+)
+
+const formatAuto = "auto"
+
+var negotiableFormats = []string{
+	service.FormatJPEG,
+	service.FormatPNG,
+	service.FormatGIF,
+	service.FormatBMP,
+	service.FormatTIFF,
+}
+
+type mediaRange struct {
+	mediaType string
+	subtype   string
+	quality   float64
+}
+
+// negotiateFormat picks the output format with the highest quality in the
+// Accept header, the source format wins among equal ones and is used when
+// nothing else is acceptable.
+func negotiateFormat(accept, source string) string {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return source
+	}
+
+	best, bestQuality := source, acceptQuality(ranges, source)
+	for _, format := range negotiableFormats {
+		if quality := acceptQuality(ranges, format); quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality of the most specific media range
+// matching the format.
+func acceptQuality(ranges []mediaRange, format string) float64 {
+	subtype := strings.TrimPrefix(contentTypes[service.FormatExt(format)], "image/")
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		var current int
+		switch {
+		case r.mediaType == "image" && r.subtype == subtype:
+			current = 2
+		case r.mediaType == "image" && r.subtype == "*":
+			current = 1
+		case r.mediaType == "*" && r.subtype == "*":
+			current = 0
+		default:
+			continue
+		}
+		if current > specificity {
+			quality, specificity = r.quality, current
+		}
+	}
+	return quality
+}
+
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok {
+			continue
+		}
+
+		r := mediaRange{mediaType: mediaType, subtype: subtype, quality: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+				r.quality = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		accept   string
+		source   string
+		expected string
+	}{
+		{name: "empty accept", accept: "", source: "png", expected: "png"},
+		{name: "browser accept", accept: "image/avif,image/webp,image/*,*/*;q=0.8", source: "png", expected: "png"},
+		{name: "exact type", accept: "image/jpeg", source: "png", expected: "jpeg"},
+		{name: "preferred by quality", accept: "image/png;q=0.5,image/gif", source: "jpeg", expected: "gif"},
+		{name: "source is not acceptable", accept: "image/png, image/jpeg;q=0", source: "jpeg", expected: "png"},
+		{name: "nothing acceptable", accept: "text/html", source: "jpeg", expected: "jpeg"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, negotiateFormat(test.accept, test.source))
+		})
+	}
+}
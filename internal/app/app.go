@@ -27,6 +27,26 @@ func New(logg *logger.Logger, lru cache.Cache, previewer *service.Previewer, con
 }
 
 func (a *App) HandleFill(w http.ResponseWriter, r *http.Request) {
+	a.handle(w, r, service.ModeFill)
+}
+
+func (a *App) HandleFit(w http.ResponseWriter, r *http.Request) {
+	a.handle(w, r, service.ModeFit)
+}
+
+func (a *App) HandlePad(w http.ResponseWriter, r *http.Request) {
+	a.handle(w, r, service.ModePad)
+}
+
+func (a *App) HandleOps(w http.ResponseWriter, r *http.Request) {
+	a.handle(w, r, service.ModeOps)
+}
+
+func (a *App) HandlePreset(w http.ResponseWriter, r *http.Request) {
+	a.handle(w, r, modePreset)
+}
+
+func (a *App) handle(w http.ResponseWriter, r *http.Request, mode string) {
 	resp := &Response{}
 	if r.Method != http.MethodGet {
 		err := fmt.Errorf("method %s not not supported on uri %s", r.Method, r.URL.Path)
@@ -34,8 +54,16 @@ func (a *App) HandleFill(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req := &Request{Protocol: a.config.Loading.Protocol}
-	req.CreateHash(r.URL.Path)
+	req := newRequest(a.config, mode)
+	if err := req.Validate(r); err != nil {
+		a.logg.Warn("app request validate", err)
+		resp.WriteError(w, err, http.StatusUnprocessableEntity)
+		return
+	}
+	req.CreateHash(req.CacheKey())
+	if req.AutoFormat {
+		w.Header().Set("Vary", "Accept")
+	}
 
 	ext, ok := a.lru.Get(req.Hash)
 	if ok {
@@ -47,19 +75,13 @@ func (a *App) HandleFill(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := req.Validate(r); err != nil {
-		a.logg.Warn("app request validate", err)
-		resp.WriteError(w, err, http.StatusUnprocessableEntity)
-		return
-	}
-
 	if err := a.previewer.Preview(r, req.ConvertToServiceImage()); err != nil {
 		a.logg.Warn("app previewer preview", err)
 		resp.WriteError(w, err, http.StatusBadGateway)
 		return
 	}
 
-	a.lru.Set(req.Hash, req.Ext)
+	a.lru.Set(req.Hash, service.FormatExt(req.Format))
 	imagePath, err := a.previewer.Storage.GetImagePath(req.ImageName)
 	if err != nil {
 		a.logg.Warn("app previewer GetImagePath", err)
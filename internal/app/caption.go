@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"image/color"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AndreiGoStorm/previewer/internal/service"
+)
+
+const (
+	captionMaxLength = 64
+	captionSize      = 24
+)
+
+// validateCaption reads the caption text and its options from the query:
+// text_size in pixels, text_color, text_bg of the box behind the text and
+// text_position with the gravity of the box.
+func (req *Request) validateCaption(query url.Values) (err error) {
+	text := query.Get("text")
+	if text == "" {
+		return nil
+	}
+	if !utf8.ValidString(text) || utf8.RuneCountInString(text) > captionMaxLength {
+		return fmt.Errorf("wrong text: %s", text)
+	}
+
+	caption := &service.Caption{
+		Text:     text,
+		Size:     captionSize,
+		Color:    color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+		Position: service.GravityBottom,
+	}
+	if size := query.Get("text_size"); size != "" {
+		caption.Size, err = strconv.ParseFloat(size, 64)
+		if err != nil || !(caption.Size >= 6 && caption.Size <= 200) {
+			return fmt.Errorf("wrong text size: %s", size)
+		}
+	}
+	if textColor := query.Get("text_color"); textColor != "" {
+		caption.Color, err = parseColor(textColor)
+		if err != nil {
+			return fmt.Errorf("wrong text color: %s", textColor)
+		}
+	}
+	if bg := query.Get("text_bg"); bg != "" {
+		background, err := parseColor(bg)
+		if err != nil {
+			return fmt.Errorf("wrong text background: %s", bg)
+		}
+		caption.Background = &background
+	}
+	if position := query.Get("text_position"); position != "" {
+		caption.Position = strings.ToLower(position)
+		if !service.ValidCaptionPosition(caption.Position) {
+			return fmt.Errorf("wrong text position: %s", position)
+		}
+	}
+
+	req.Caption = caption
+	return nil
+}
+
+func captionKey(caption *service.Caption) string {
+	key := fmt.Sprintf("txt:%q,%s,%02x%02x%02x,%s", caption.Text, formatFloat(caption.Size),
+		caption.Color.R, caption.Color.G, caption.Color.B, caption.Position)
+	if bg := caption.Background; bg != nil {
+		key += fmt.Sprintf(",%02x%02x%02x", bg.R, bg.G, bg.B)
+	}
+	return key
+}
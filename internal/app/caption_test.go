@@ -0,0 +1,75 @@
+package app
+
+import (
+	"image/color"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AndreiGoStorm/previewer/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestValidateCaption(t *testing.T) {
+	t.Run("validate caption: defaults", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateCaption(url.Values{"text": {"SOLD"}})
+		require.NoError(t, err)
+		require.Equal(t, &service.Caption{
+			Text:     "SOLD",
+			Size:     24,
+			Color:    color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+			Position: "bottom",
+		}, req.Caption)
+	})
+
+	t.Run("validate caption: options", func(t *testing.T) {
+		req := &Request{}
+		query := url.Values{
+			"text":          {"€ 19,99"},
+			"text_size":     {"32"},
+			"text_color":    {"000"},
+			"text_bg":       {"#ffcc00"},
+			"text_position": {"Top-Right"},
+		}
+		err := req.validateCaption(query)
+		require.NoError(t, err)
+		require.Equal(t, "€ 19,99", req.Caption.Text)
+		require.Equal(t, 32.0, req.Caption.Size)
+		require.Equal(t, color.NRGBA{A: 255}, req.Caption.Color)
+		require.Equal(t, &color.NRGBA{R: 255, G: 204, A: 255}, req.Caption.Background)
+		require.Equal(t, "top-right", req.Caption.Position)
+		require.Equal(t, `txt:"€ 19,99",32,000000,top-right,ffcc00`, captionKey(req.Caption))
+	})
+
+	t.Run("validate caption: without text", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateCaption(url.Values{"text_size": {"32"}})
+		require.NoError(t, err)
+		require.Nil(t, req.Caption)
+	})
+
+	long := strings.Repeat("a", 65)
+	for _, test := range []struct {
+		name     string
+		query    url.Values
+		expected string
+	}{
+		{name: "long text", query: url.Values{"text": {long}}, expected: "wrong text: " + long},
+		{name: "size", query: url.Values{"text": {"SOLD"}, "text_size": {"2"}}, expected: "wrong text size: 2"},
+		{name: "color", query: url.Values{"text": {"SOLD"}, "text_color": {"red"}}, expected: "wrong text color: red"},
+		{name: "background", query: url.Values{"text": {"SOLD"}, "text_bg": {"12"}}, expected: "wrong text background: 12"},
+		{
+			name:     "position",
+			query:    url.Values{"text": {"SOLD"}, "text_position": {"smart"}},
+			expected: "wrong text position: smart",
+		},
+	} {
+		t.Run("error validate caption: "+test.name, func(t *testing.T) {
+			req := &Request{}
+			err := req.validateCaption(test.query)
+			require.Error(t, err)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/AndreiGoStorm/previewer/internal/config"
+	"github.com/AndreiGoStorm/previewer/internal/service"
+)
+
+// ValidateConfig checks the image defaults and the presets of the config, they
+// are used as is for every request which does not set its own options.
+func ValidateConfig(conf *config.Config) error {
+	if conf.Image.JPEGQuality < 1 || conf.Image.JPEGQuality > 100 {
+		return fmt.Errorf("wrong jpeg quality: %d", conf.Image.JPEGQuality)
+	}
+	if !service.ValidCompression(conf.Image.PNGCompression) {
+		return fmt.Errorf("wrong png compression: %s", conf.Image.PNGCompression)
+	}
+	if !service.ValidFilter(conf.Resize.Filter) {
+		return fmt.Errorf("wrong filter: %s", conf.Resize.Filter)
+	}
+	for _, name := range slices.Sorted(maps.Keys(conf.Presets)) {
+		if err := newRequest(conf, modePreset).validatePresetConfig(name); err != nil {
+			return fmt.Errorf("wrong preset %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/AndreiGoStorm/previewer/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() *config.Config {
+		return &config.Config{
+			Resize: config.Resize{Filter: "lanczos"},
+			Image:  config.Image{JPEGQuality: 95, PNGCompression: "best_compression"},
+		}
+	}
+
+	t.Run("validate config: correct config", func(t *testing.T) {
+		require.NoError(t, ValidateConfig(valid()))
+	})
+
+	t.Run("error validate config: wrong jpeg quality", func(t *testing.T) {
+		conf := valid()
+		conf.Image.JPEGQuality = 101
+		err := ValidateConfig(conf)
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong jpeg quality: 101")
+	})
+
+	t.Run("error validate config: wrong png compression", func(t *testing.T) {
+		conf := valid()
+		conf.Image.PNGCompression = "best-compression"
+		err := ValidateConfig(conf)
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong png compression: best-compression")
+	})
+
+	t.Run("error validate config: wrong filter", func(t *testing.T) {
+		conf := valid()
+		conf.Resize.Filter = "lanczos3"
+		err := ValidateConfig(conf)
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong filter: lanczos3")
+	})
+
+	t.Run("validate config: correct presets", func(t *testing.T) {
+		conf := valid()
+		conf.Presets = map[string]config.Preset{
+			"avatar": {Mode: "fill", Width: 64, Height: 64, Gravity: "smart", Effect: "grayscale", Format: "png"},
+			"tile":   {Mode: "pad", Width: 300, Height: 300, Background: "000000", Filter: "nearest"},
+		}
+		require.NoError(t, ValidateConfig(conf))
+	})
+
+	for _, test := range []struct {
+		preset config.Preset
+		err    string
+	}{
+		{preset: config.Preset{Mode: "stretch", Width: 64, Height: 64}, err: "wrong preset mode: stretch"},
+		{preset: config.Preset{Width: 0, Height: 0}, err: "wrong size: width and height are both auto"},
+		{preset: config.Preset{Width: 64, Height: 64, Gravity: "middle"}, err: "wrong gravity: middle"},
+		{preset: config.Preset{Width: 64, Height: 64, Filter: "lanczos3"}, err: "wrong filter: lanczos3"},
+		{preset: config.Preset{Width: 64, Height: 64, Format: "jpg2000"}, err: "wrong format: jpg2000"},
+		{preset: config.Preset{Width: 64, Height: 64, Effect: "vintage"}, err: "wrong effect: vintage"},
+	} {
+		t.Run("error validate config: "+test.err, func(t *testing.T) {
+			conf := valid()
+			conf.Presets = map[string]config.Preset{"avatar": test.preset}
+			err := ValidateConfig(conf)
+			require.Error(t, err)
+			require.EqualError(t, err, "wrong preset avatar: "+test.err)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndreiGoStorm/previewer/internal/service"
+)
+
+// parseOperations reads the chain of operations from the beginning of the url
+// up to the first segment that is not a registered operation, the rest of the
+// url is returned as the loading url.
+func parseOperations(url string) ([]service.Operation, string, error) {
+	segments := strings.Split(url, "/")
+	ops := make([]service.Operation, 0, len(segments))
+	for i, segment := range segments {
+		name, args, _ := strings.Cut(segment, ":")
+		if !service.IsOperation(name) {
+			if len(ops) == 0 {
+				return nil, "", fmt.Errorf("operations are empty")
+			}
+			return ops, strings.Join(segments[i:], "/"), nil
+		}
+
+		op, err := service.NewOperation(name, args)
+		if err != nil {
+			return nil, "", err
+		}
+		ops = append(ops, op)
+	}
+	return ops, "", nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOperations(t *testing.T) {
+	t.Run("parse operations: chain and loading url", func(t *testing.T) {
+		ops, url, err := parseOperations("resize:300x200/blur:2.50/grayscale/localhost/images/image.jpg")
+		require.NoError(t, err)
+		require.Equal(t, "localhost/images/image.jpg", url)
+		require.Len(t, ops, 3)
+		require.Equal(t, "resize:300x200", ops[0].String())
+		require.Equal(t, "blur:2.5", ops[1].String())
+		require.Equal(t, "grayscale", ops[2].String())
+	})
+
+	t.Run("parse operations: without loading url", func(t *testing.T) {
+		ops, url, err := parseOperations("fit:100x100")
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+		require.Empty(t, url)
+	})
+
+	t.Run("error parse operations: empty chain", func(t *testing.T) {
+		_, _, err := parseOperations("localhost/image.jpg")
+		require.Error(t, err)
+		require.EqualError(t, err, "operations are empty")
+	})
+
+	t.Run("error parse operations: wrong arguments", func(t *testing.T) {
+		_, _, err := parseOperations("resize:300/localhost/image.jpg")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong operation arguments: resize:300")
+	})
+}
+
+func TestRequestCacheKeyOperations(t *testing.T) {
+	ops, _, err := parseOperations("resize:0300x200/blur:2.0/grayscale")
+	require.NoError(t, err)
+	req := &Request{
+		Mode:            "ops",
+		Operations:      ops,
+		AutoOrientation: true,
+		Filter:          "lanczos",
+		Format:          "png",
+		Compression:     "default",
+		URL:             "http://localhost/image.png",
+	}
+	require.Equal(t, "ops/resize:300x200/blur:2/grayscale/o:true/r:lanczos/f:png/c:default/http://localhost/image.png",
+		req.CacheKey())
+}
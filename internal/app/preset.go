@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AndreiGoStorm/previewer/internal/config"
+	"github.com/AndreiGoStorm/previewer/internal/service"
+)
+
+const modePreset = "preset"
+
+// validatePreset expands the preset from the beginning of the url into the
+// request, options of the preset are used unless they are set in the query.
+func (req *Request) validatePreset(presetURL string, query url.Values) (loadingURL string, err error) {
+	name, loadingURL, _ := strings.Cut(presetURL, "/")
+	preset, ok := req.Presets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown preset: %s", name)
+	}
+
+	req.Mode = strings.ToLower(preset.Mode)
+	switch req.Mode {
+	case "":
+		req.Mode = service.ModeFill
+	case service.ModeFill, service.ModeFit, service.ModePad:
+	default:
+		return "", fmt.Errorf("wrong preset mode: %s", preset.Mode)
+	}
+
+	_, err = req.validateSize(fmt.Sprintf("%d/%d", preset.Width, preset.Height))
+	if err != nil {
+		return "", err
+	}
+
+	for key, value := range presetOptions(preset) {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+	return loadingURL, nil
+}
+
+// validatePresetConfig checks the options of the preset by the validators of
+// the request, the preset is not bound to a loading url.
+func (req *Request) validatePresetConfig(name string) error {
+	query := url.Values{}
+	if _, err := req.validatePreset(name, query); err != nil {
+		return err
+	}
+	return req.validateOptions(query, "")
+}
+
+// presetOptions converts the preset into query options of the request.
+func presetOptions(preset config.Preset) map[string]string {
+	options := map[string]string{
+		"gravity":      preset.Gravity,
+		"bg":           preset.Background,
+		"filter":       preset.Filter,
+		"format":       preset.Format,
+		"effect":       preset.Effect,
+		"watermark":    preset.Watermark,
+		"mask":         preset.Mask,
+		"border_color": preset.BorderColor,
+	}
+	if preset.Enlarge != nil {
+		options["enlarge"] = strconv.FormatBool(*preset.Enlarge)
+	}
+	if preset.Trim != nil {
+		options["trim"] = strconv.Itoa(*preset.Trim)
+	}
+	if preset.Radius > 0 {
+		options["radius"] = strconv.Itoa(preset.Radius)
+	}
+	if preset.Border > 0 {
+		options["border"] = strconv.Itoa(preset.Border)
+	}
+	if preset.Shadow > 0 {
+		options["shadow"] = formatFloat(preset.Shadow)
+	}
+	if preset.Quality > 0 {
+		options["quality"] = strconv.Itoa(preset.Quality)
+	}
+
+	for key, value := range options {
+		if value == "" {
+			delete(options, key)
+		}
+	}
+	return options
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AndreiGoStorm/previewer/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestValidatePreset(t *testing.T) {
+	enlarge := false
+	presets := map[string]config.Preset{
+		"avatar_small": {Mode: "fill", Width: 64, Height: 64, Gravity: "smart", Quality: 80},
+		"product_tile": {Mode: "pad", Width: 300, Height: 300, Background: "000000", Enlarge: &enlarge},
+		"broken":       {Mode: "stretch", Width: 10, Height: 10},
+	}
+	newRequest := func(path string) (*Request, *http.Request) {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Pattern = "/p/"
+		return &Request{Protocol: "http", Mode: modePreset, Enlarge: true, Quality: 95, Presets: presets}, r
+	}
+
+	t.Run("validate preset: expand preset", func(t *testing.T) {
+		req, r := newRequest("/p/avatar_small/localhost/image.jpg")
+		err := req.Validate(r)
+		require.NoError(t, err)
+		require.Equal(t, "fill", req.Mode)
+		require.Equal(t, 64, req.Width)
+		require.Equal(t, 64, req.Height)
+		require.Equal(t, "smart", req.Gravity)
+		require.Equal(t, 80, req.Quality)
+		require.Equal(t, "http://localhost/image.jpg", req.URL)
+
+		direct, r := newRequest("/fill/64/64/localhost/image.jpg?gravity=smart&quality=80")
+		direct.Mode = "fill"
+		r.Pattern = "/fill/"
+		err = direct.Validate(r)
+		require.NoError(t, err)
+		require.Equal(t, direct.CacheKey(), req.CacheKey())
+	})
+
+	t.Run("validate preset: query overrides preset", func(t *testing.T) {
+		req, r := newRequest("/p/product_tile/localhost/image.jpg?bg=ffffff")
+		err := req.Validate(r)
+		require.NoError(t, err)
+		require.Equal(t, "pad", req.Mode)
+		require.False(t, req.Enlarge)
+		require.Equal(t, uint8(255), req.Background.R)
+	})
+
+	t.Run("error validate preset: unknown preset", func(t *testing.T) {
+		req, r := newRequest("/p/avatar_large/localhost/image.jpg")
+		err := req.Validate(r)
+		require.Error(t, err)
+		require.EqualError(t, err, "unknown preset: avatar_large")
+	})
+
+	t.Run("error validate preset: wrong mode", func(t *testing.T) {
+		req, r := newRequest("/p/broken/localhost/image.jpg")
+		err := req.Validate(r)
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong preset mode: stretch")
+	})
+}
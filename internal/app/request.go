@@ -4,23 +4,70 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"image/color"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
 	"strings"
 
+	"github.com/AndreiGoStorm/previewer/internal/config"
 	"github.com/AndreiGoStorm/previewer/internal/service"
 )
 
+var adjustments = []string{"blur", "sharpen", "brightness", "contrast", "gamma", "saturation"}
+
 type Request struct {
-	Protocol  string
-	Hash      string
-	Width     int
-	Height    int
-	URL       string
-	Ext       string
-	ImageName string
+	Protocol        string
+	Mode            string
+	Hash            string
+	Width           int
+	Height          int
+	Gravity         string
+	FocalPoint      *service.FocalPoint
+	Background      color.NRGBA
+	Enlarge         bool
+	AutoOrientation bool
+	Rotate          int
+	Flip            string
+	Filter          string
+	Operations      []service.Operation
+	Preprocessing   []service.Operation
+	Adjustments     []service.Operation
+	Caption         *service.Caption
+	Radius          int
+	Mask            string
+	Border          *service.Border
+	Shadow          float64
+	Watermark       *service.Watermark
+	WatermarkConfig config.Watermark
+	Presets         map[string]config.Preset
+	MaxFrames       int
+	MaxPixels       int
+	URL             string
+	Ext             string
+	Format          string
+	AutoFormat      bool
+	Quality         int
+	Compression     string
+	ImageName       string
+}
+
+// newRequest creates the request of the mode with the defaults of the config.
+func newRequest(conf *config.Config, mode string) *Request {
+	return &Request{
+		Protocol:        conf.Loading.Protocol,
+		Mode:            mode,
+		Enlarge:         conf.Resize.EnlargeEnabled(),
+		Filter:          conf.Resize.Filter,
+		Quality:         conf.Image.JPEGQuality,
+		Compression:     conf.Image.PNGCompression,
+		AutoOrientation: conf.Image.AutoOrientationEnabled(),
+		WatermarkConfig: conf.Watermark,
+		Presets:         conf.Presets,
+		MaxFrames:       conf.Animation.MaxFrames,
+		MaxPixels:       conf.Animation.MaxPixels,
+	}
 }
 
 func (req *Request) CreateHash(url string) {
@@ -28,33 +75,182 @@ func (req *Request) CreateHash(url string) {
 	h1.Write([]byte(url))
 	hash := h1.Sum(nil)
 	req.Hash = hex.EncodeToString(hash)
+	req.ImageName = req.Hash + service.FormatExt(req.Format)
+}
+
+// CacheKey builds the key the preview is cached under, every mode has its own namespace.
+func (req *Request) CacheKey() string {
+	parts := []string{req.Mode}
+	if req.Mode == service.ModeOps {
+		for _, op := range req.Operations {
+			parts = append(parts, op.String())
+		}
+	} else {
+		parts = append(parts, strconv.Itoa(req.Width), strconv.Itoa(req.Height),
+			"g:"+req.Gravity, "e:"+strconv.FormatBool(req.Enlarge))
+	}
+	for _, op := range req.Preprocessing {
+		parts = append(parts, op.String())
+	}
+	parts = append(parts, "o:"+strconv.FormatBool(req.AutoOrientation))
+	if req.Rotate != 0 {
+		parts = append(parts, "rot:"+strconv.Itoa(req.Rotate))
+	}
+	if req.Flip != "" {
+		parts = append(parts, "flip:"+req.Flip)
+	}
+	for _, op := range req.Adjustments {
+		parts = append(parts, op.String())
+	}
+	if req.Caption != nil {
+		parts = append(parts, captionKey(req.Caption))
+	}
+	if wm := req.Watermark; wm != nil {
+		parts = append(parts, fmt.Sprintf("wm:%s,%s,%d", wm.Position, formatFloat(wm.Opacity), wm.Margin))
+	}
+	if req.Radius > 0 {
+		parts = append(parts, "rad:"+strconv.Itoa(req.Radius))
+	}
+	if req.Mask != "" {
+		parts = append(parts, "mask:"+req.Mask)
+	}
+	if b := req.Border; b != nil {
+		parts = append(parts, fmt.Sprintf("border:%d,%02x%02x%02x", b.Width, b.Color.R, b.Color.G, b.Color.B))
+	}
+	if req.Shadow > 0 {
+		parts = append(parts, "shadow:"+formatFloat(req.Shadow))
+	}
+	parts = append(parts, "r:"+req.Filter, "f:"+req.Format)
+	switch req.Format {
+	case service.FormatJPEG:
+		parts = append(parts, "q:"+strconv.Itoa(req.Quality))
+	case service.FormatPNG:
+		parts = append(parts, "c:"+req.Compression)
+	}
+	if req.FocalPoint != nil {
+		parts = append(parts, "fp:"+formatFloat(req.FocalPoint.X)+","+formatFloat(req.FocalPoint.Y))
+	}
+	if req.Mode == service.ModePad {
+		bg := req.Background
+		parts = append(parts, fmt.Sprintf("bg:%02x%02x%02x%02x", bg.R, bg.G, bg.B, bg.A))
+	}
+	parts = append(parts, req.URL)
+	return strings.Join(parts, "/")
 }
 
 func (req *Request) Validate(r *http.Request) (err error) {
-	url := strings.TrimLeft(r.URL.Path, r.Pattern)
-	parts := strings.Split(url, "/")
-	if len(parts) < 2 {
-		return fmt.Errorf("wrong loading url: %s", url)
+	url := strings.TrimPrefix(r.URL.Path, r.Pattern)
+	query := r.URL.Query()
+	var loadingURL string
+	switch req.Mode {
+	case service.ModeOps:
+		req.Operations, loadingURL, err = parseOperations(url)
+	case modePreset:
+		loadingURL, err = req.validatePreset(url, query)
+	default:
+		loadingURL, err = req.validateSize(url)
+	}
+	if err != nil {
+		return err
 	}
 
-	err = req.validateWidth(parts[0])
+	err = req.validateURL(loadingURL)
 	if err != nil {
 		return err
 	}
 
-	err = req.validateHeight(parts[1])
+	err = req.validateExt(req.URL)
 	if err != nil {
 		return err
 	}
 
-	widthTrim := strings.TrimLeft(url, fmt.Sprintf("%s/", parts[0]))
-	heightTrim := strings.TrimLeft(widthTrim, fmt.Sprintf("%s/", parts[1]))
-	err = req.validateURL(heightTrim)
+	return req.validateOptions(query, r.Header.Get("Accept"))
+}
+
+// validateOptions validates the options of the query, they are shared by all
+// modes and presets.
+func (req *Request) validateOptions(query url.Values, accept string) (err error) {
+	err = req.validateFormat(query.Get("format"), accept)
 	if err != nil {
 		return err
 	}
 
-	err = req.validateExt(req.URL)
+	err = req.validateMask(query.Get("radius"), query.Get("mask"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateBorder(query.Get("border"), query.Get("border_color"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateShadow(query.Get("shadow"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateQuality(query.Get("quality"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateTrim(query.Get("trim"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateGravity(query.Get("gravity"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateFocalPoint(query.Get("fp"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateBackground(query.Get("bg"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateEnlarge(query.Get("enlarge"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateFilter(query.Get("filter"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateRotate(query.Get("rotate"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateFlip(query.Get("flip"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateAdjustments(query)
+	if err != nil {
+		return err
+	}
+
+	err = req.validateEffects(query.Get("effect"))
+	if err != nil {
+		return err
+	}
+
+	err = req.validateCaption(query)
+	if err != nil {
+		return err
+	}
+
+	err = req.validateWatermark(query.Get("watermark"))
 	if err != nil {
 		return err
 	}
@@ -62,23 +258,51 @@ func (req *Request) Validate(r *http.Request) (err error) {
 	return nil
 }
 
+// validateSize reads the width and height from the beginning of the url and
+// returns the rest of the url as the loading url.
+func (req *Request) validateSize(url string) (loadingURL string, err error) {
+	parts := strings.SplitN(url, "/", 3)
+	if len(parts) < 2 {
+		return "", fmt.Errorf("wrong loading url: %s", url)
+	}
+
+	err = req.validateWidth(parts[0])
+	if err != nil {
+		return "", err
+	}
+
+	err = req.validateHeight(parts[1])
+	if err != nil {
+		return "", err
+	}
+
+	if req.Width == 0 && req.Height == 0 {
+		return "", fmt.Errorf("wrong size: width and height are both auto")
+	}
+
+	if len(parts) == 3 {
+		loadingURL = parts[2]
+	}
+	return loadingURL, nil
+}
+
 func (req *Request) validateWidth(width string) (err error) {
-	req.Width, err = strconv.Atoi(width)
+	req.Width, err = parseSize(width)
 	if err != nil {
 		return fmt.Errorf("wrong width: %s", width)
 	}
-	if req.Width <= 0 || req.Width >= 10000 {
+	if req.Width < 0 || req.Width >= 10000 {
 		return fmt.Errorf("wrong width: %d", req.Width)
 	}
 	return
 }
 
 func (req *Request) validateHeight(height string) (err error) {
-	req.Height, err = strconv.Atoi(height)
+	req.Height, err = parseSize(height)
 	if err != nil {
 		return fmt.Errorf("wrong height: %s", height)
 	}
-	if req.Height <= 0 || req.Height >= 10000 {
+	if req.Height < 0 || req.Height >= 10000 {
 		return fmt.Errorf("wrong height: %d", req.Height)
 	}
 	return
@@ -103,20 +327,336 @@ func (req *Request) validateExt(u string) (err error) {
 		return fmt.Errorf("loading image extension is empty")
 	}
 
-	extension := strings.TrimLeft(req.Ext, ".")
-	if !strings.Contains("jpg,jpeg,png,gif", extension) { //nolint:gocritic
-		return fmt.Errorf("loading image has wrong extension: %s", extension)
+	if !service.ValidSourceExt(req.Ext) {
+		return fmt.Errorf("loading image has wrong extension: %s", strings.TrimPrefix(req.Ext, "."))
+	}
+	return
+}
+
+func (req *Request) validateFormat(format, accept string) (err error) {
+	// images of the formats which can not be encoded, like webp, become png.
+	source, ok := service.ParseFormat(req.Ext)
+	if !ok {
+		source = service.FormatPNG
+	}
+	switch strings.ToLower(format) {
+	case "":
+		req.Format = source
+		return
+	case formatAuto:
+		req.Format = negotiateFormat(accept, source)
+		req.AutoFormat = true
+		return
+	}
+
+	req.Format, ok = service.ParseFormat(format)
+	if !ok {
+		return fmt.Errorf("wrong format: %s", format)
+	}
+	return
+}
+
+// validateMask reads the corner radius and the mask, the masked image has
+// transparent areas, so it is always encoded as png.
+func (req *Request) validateMask(radius, mask string) (err error) {
+	if radius != "" {
+		req.Radius, err = strconv.Atoi(radius)
+		if err != nil || req.Radius < 0 || req.Radius >= 10000 {
+			return fmt.Errorf("wrong radius: %s", radius)
+		}
+	}
+
+	req.Mask = strings.ToLower(mask)
+	if !service.ValidMask(req.Mask) {
+		return fmt.Errorf("wrong mask: %s", mask)
+	}
+
+	if req.Radius > 0 || req.Mask != "" {
+		req.Format = service.FormatPNG
+	}
+	return nil
+}
+
+// validateBorder reads the width of the border in pixels and its color, the
+// border is black by default.
+func (req *Request) validateBorder(width, borderColor string) (err error) {
+	if width == "" {
+		return nil
+	}
+
+	border := &service.Border{Color: color.NRGBA{A: 255}}
+	border.Width, err = strconv.Atoi(width)
+	if err != nil || border.Width < 1 || border.Width > 100 {
+		return fmt.Errorf("wrong border: %s", width)
+	}
+	if borderColor != "" {
+		border.Color, err = parseColor(borderColor)
+		if err != nil {
+			return fmt.Errorf("wrong border color: %s", borderColor)
+		}
+	}
+	req.Border = border
+	return nil
+}
+
+// validateShadow reads the blur sigma of the drop shadow, the shadow is drawn
+// on the transparent canvas, so the image is encoded as png.
+func (req *Request) validateShadow(shadow string) (err error) {
+	if shadow == "" {
+		return nil
+	}
+
+	req.Shadow, err = strconv.ParseFloat(shadow, 64)
+	if err != nil || !(req.Shadow > 0 && req.Shadow <= 50) {
+		return fmt.Errorf("wrong shadow: %s", shadow)
+	}
+	req.Format = service.FormatPNG
+	return nil
+}
+
+func (req *Request) validateQuality(quality string) (err error) {
+	if quality == "" {
+		return
+	}
+
+	req.Quality, err = strconv.Atoi(quality)
+	if err != nil || req.Quality < 1 || req.Quality > 100 {
+		return fmt.Errorf("wrong quality: %s", quality)
+	}
+	return nil
+}
+
+// validateTrim adds trimming of the uniform border before resizing, the value
+// is the tolerance of the border color.
+func (req *Request) validateTrim(tolerance string) error {
+	if tolerance == "" {
+		return nil
+	}
+
+	op, err := service.NewOperation("trim", tolerance)
+	if err != nil {
+		return fmt.Errorf("wrong trim: %s", tolerance)
+	}
+	req.Preprocessing = append(req.Preprocessing, op)
+	return nil
+}
+
+func (req *Request) validateGravity(gravity string) (err error) {
+	req.Gravity = strings.ToLower(gravity)
+	if req.Gravity == "" {
+		req.Gravity = service.GravityCenter
+	}
+	if !service.ValidGravity(req.Gravity) {
+		return fmt.Errorf("wrong gravity: %s", gravity)
+	}
+	return
+}
+
+func (req *Request) validateFocalPoint(fp string) (err error) {
+	if fp == "" {
+		return
+	}
+
+	coords := strings.Split(fp, ",")
+	if len(coords) != 2 {
+		return fmt.Errorf("wrong focal point: %s", fp)
+	}
+	x, errX := strconv.ParseFloat(coords[0], 64)
+	y, errY := strconv.ParseFloat(coords[1], 64)
+	if errX != nil || errY != nil || !(x >= 0 && x <= 1 && y >= 0 && y <= 1) {
+		return fmt.Errorf("wrong focal point: %s", fp)
+	}
+	req.FocalPoint = &service.FocalPoint{X: x, Y: y}
+	return
+}
+
+func (req *Request) validateBackground(bg string) (err error) {
+	switch strings.ToLower(bg) {
+	case "":
+		req.Background = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+		return
+	case "transparent":
+		if req.Format != service.FormatPNG {
+			return fmt.Errorf("transparent background is supported only for png")
+		}
+		req.Background = color.NRGBA{}
+		return
+	}
+
+	req.Background, err = parseColor(bg)
+	if err != nil {
+		return fmt.Errorf("wrong background: %s", bg)
+	}
+	return nil
+}
+
+func (req *Request) validateEnlarge(enlarge string) (err error) {
+	if enlarge == "" {
+		return
+	}
+
+	req.Enlarge, err = strconv.ParseBool(enlarge)
+	if err != nil {
+		return fmt.Errorf("wrong enlarge: %s", enlarge)
+	}
+	return
+}
+
+func (req *Request) validateFilter(filter string) (err error) {
+	if filter == "" {
+		return
+	}
+
+	req.Filter = strings.ToLower(filter)
+	if !service.ValidFilter(req.Filter) {
+		return fmt.Errorf("wrong filter: %s", filter)
 	}
-	req.ImageName = req.Hash + req.Ext
 	return
 }
 
+func (req *Request) validateRotate(rotate string) (err error) {
+	if rotate == "" {
+		return
+	}
+
+	req.Rotate, err = strconv.Atoi(rotate)
+	if err != nil || !service.ValidRotate(req.Rotate) {
+		return fmt.Errorf("wrong rotate: %s", rotate)
+	}
+	return nil
+}
+
+func (req *Request) validateFlip(flip string) (err error) {
+	req.Flip = strings.ToLower(flip)
+	if !service.ValidFlip(req.Flip) {
+		return fmt.Errorf("wrong flip: %s", flip)
+	}
+	return
+}
+
+// validateAdjustments converts the adjustment options of the query into
+// operations applied after resizing.
+func (req *Request) validateAdjustments(query url.Values) error {
+	for _, name := range adjustments {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		op, err := service.NewOperation(name, value)
+		if err != nil {
+			return fmt.Errorf("wrong %s: %s", name, value)
+		}
+		req.Adjustments = append(req.Adjustments, op)
+	}
+	return nil
+}
+
+// validateEffects adds the comma separated color effects after the adjustments.
+func (req *Request) validateEffects(effects string) error {
+	if effects == "" {
+		return nil
+	}
+
+	for _, effect := range strings.Split(strings.ToLower(effects), ",") {
+		if !service.ValidEffect(effect) {
+			return fmt.Errorf("wrong effect: %s", effect)
+		}
+
+		op, err := service.NewOperation(effect, "")
+		if err != nil {
+			return err
+		}
+		req.Adjustments = append(req.Adjustments, op)
+	}
+	return nil
+}
+
+// validateWatermark enables the configured watermark, the value is a boolean
+// or the position of the watermark overriding the configured one.
+func (req *Request) validateWatermark(watermark string) error {
+	if watermark == "" {
+		return nil
+	}
+
+	position := strings.ToLower(watermark)
+	if enabled, err := strconv.ParseBool(watermark); err == nil {
+		if !enabled {
+			return nil
+		}
+		position = req.WatermarkConfig.Position
+	}
+	if !service.ValidWatermarkPosition(position) {
+		return fmt.Errorf("wrong watermark: %s", watermark)
+	}
+	if req.WatermarkConfig.Path == "" {
+		return fmt.Errorf("watermark is not configured")
+	}
+
+	req.Watermark = &service.Watermark{
+		Path:     req.WatermarkConfig.Path,
+		Position: position,
+		Opacity:  req.WatermarkConfig.Opacity,
+		Margin:   req.WatermarkConfig.Margin,
+	}
+	return nil
+}
+
 func (req *Request) ConvertToServiceImage() *service.Image {
 	return &service.Image{
-		Width:     req.Width,
-		Height:    req.Height,
-		URL:       req.URL,
-		Ext:       req.Ext,
-		ImageName: req.ImageName,
+		Mode:            req.Mode,
+		Width:           req.Width,
+		Height:          req.Height,
+		Gravity:         req.Gravity,
+		FocalPoint:      req.FocalPoint,
+		Background:      req.Background,
+		Enlarge:         req.Enlarge,
+		AutoOrientation: req.AutoOrientation,
+		Rotate:          req.Rotate,
+		Flip:            req.Flip,
+		Filter:          req.Filter,
+		Operations:      req.Operations,
+		Preprocessing:   req.Preprocessing,
+		Adjustments:     req.Adjustments,
+		Caption:         req.Caption,
+		Radius:          req.Radius,
+		Mask:            req.Mask,
+		Border:          req.Border,
+		Shadow:          req.Shadow,
+		Watermark:       req.Watermark,
+		MaxFrames:       req.MaxFrames,
+		MaxPixels:       req.MaxPixels,
+		Quality:         req.Quality,
+		Compression:     req.Compression,
+		URL:             req.URL,
+		Ext:             req.Ext,
+		ImageName:       req.ImageName,
+	}
+}
+
+// parseSize converts a dimension from url, 0 or auto means that the dimension
+// is derived from the aspect ratio of the source image.
+func parseSize(size string) (int, error) {
+	if strings.EqualFold(size, "auto") {
+		return 0, nil
 	}
+	return strconv.Atoi(size)
+}
+
+// parseColor converts a hex color code in rgb or rrggbb form with an optional
+// leading hash into an opaque color.
+func parseColor(hex string) (color.NRGBA, error) {
+	code := strings.TrimPrefix(hex, "#")
+	if len(code) == 3 {
+		code = string([]byte{code[0], code[0], code[1], code[1], code[2], code[2]})
+	}
+	rgb, err := strconv.ParseUint(code, 16, 32)
+	if err != nil || len(code) != 6 {
+		return color.NRGBA{}, fmt.Errorf("wrong color: %s", hex)
+	}
+	return color.NRGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
@@ -1,8 +1,16 @@
 package app
 
 import (
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/AndreiGoStorm/previewer/internal/config"
+	"github.com/AndreiGoStorm/previewer/internal/service"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,6 +22,14 @@ func TestRequestValidateWidth(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("validate width: auto width", func(t *testing.T) {
+		for _, width := range []string{"0", "auto", "AUTO"} {
+			err := req.validateWidth(width)
+			require.NoError(t, err)
+			require.Equal(t, 0, req.Width)
+		}
+	})
+
 	t.Run("error validate width: wrong width", func(t *testing.T) {
 		width := "wrong width"
 		err := req.validateWidth(width)
@@ -43,6 +59,14 @@ func TestRequestValidateHeight(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("validate height: auto height", func(t *testing.T) {
+		for _, height := range []string{"0", "auto"} {
+			err := req.validateHeight(height)
+			require.NoError(t, err)
+			require.Equal(t, 0, req.Height)
+		}
+	})
+
 	t.Run("error validate height: wrong height", func(t *testing.T) {
 		height := "wrong height"
 		err := req.validateHeight(height)
@@ -89,6 +113,13 @@ func TestRequestValidateURL(t *testing.T) {
 
 func TestRequestValidateExt(t *testing.T) {
 	req := &Request{}
+	t.Run("validate url: supported extensions", func(t *testing.T) {
+		for _, ext := range []string{"jpg", "JPEG", "png", "gif", "webp", "bmp", "tiff", "tif", "svg"} {
+			err := req.validateExt("localhost/image." + ext)
+			require.NoError(t, err)
+		}
+	})
+
 	t.Run("error validate url: empty extension", func(t *testing.T) {
 		err := req.validateExt("github.com/stretchr/testify/require")
 		require.Error(t, err)
@@ -101,3 +132,487 @@ func TestRequestValidateExt(t *testing.T) {
 		require.EqualError(t, err, "loading image has wrong extension: ttf")
 	})
 }
+
+func TestRequestCreateHash(t *testing.T) {
+	t.Run("fill and fit have different cache keys", func(t *testing.T) {
+		fill := &Request{Mode: "fill", Width: 300, Height: 200, URL: "http://localhost/image.jpg", Format: "jpeg"}
+		fit := &Request{Mode: "fit", Width: 300, Height: 200, URL: "http://localhost/image.jpg", Format: "jpeg"}
+		fill.CreateHash(fill.CacheKey())
+		fit.CreateHash(fit.CacheKey())
+		require.NotEqual(t, fill.Hash, fit.Hash)
+		require.Equal(t, fill.Hash+".jpeg", fill.ImageName)
+	})
+}
+
+func TestRequestValidateGravity(t *testing.T) {
+	req := &Request{}
+	t.Run("validate gravity: default center", func(t *testing.T) {
+		err := req.validateGravity("")
+		require.NoError(t, err)
+		require.Equal(t, "center", req.Gravity)
+	})
+
+	t.Run("validate gravity: correct gravity", func(t *testing.T) {
+		err := req.validateGravity("Bottom-Right")
+		require.NoError(t, err)
+		require.Equal(t, "bottom-right", req.Gravity)
+	})
+
+	t.Run("validate gravity: smart", func(t *testing.T) {
+		err := req.validateGravity("smart")
+		require.NoError(t, err)
+		require.Equal(t, "smart", req.Gravity)
+	})
+
+	t.Run("error validate gravity: wrong gravity", func(t *testing.T) {
+		err := req.validateGravity("middle")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong gravity: middle")
+	})
+}
+
+func TestRequestValidateFocalPoint(t *testing.T) {
+	t.Run("validate focal point: empty", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateFocalPoint("")
+		require.NoError(t, err)
+		require.Nil(t, req.FocalPoint)
+	})
+
+	t.Run("validate focal point: correct focal point", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateFocalPoint("0.3,0.7")
+		require.NoError(t, err)
+		require.Equal(t, 0.3, req.FocalPoint.X)
+		require.Equal(t, 0.7, req.FocalPoint.Y)
+	})
+
+	for _, fp := range []string{"0.3", "0.3,0.7,1", "a,b", "1.5,0.5", "0.5,-0.1", "NaN,0.5"} {
+		t.Run("error validate focal point: "+fp, func(t *testing.T) {
+			req := &Request{}
+			err := req.validateFocalPoint(fp)
+			require.Error(t, err)
+			require.EqualError(t, err, "wrong focal point: "+fp)
+		})
+	}
+}
+
+func TestRequestCacheKey(t *testing.T) {
+	t.Run("different focal points have different cache keys", func(t *testing.T) {
+		first := &Request{Mode: "fill", Width: 300, Height: 200, FocalPoint: &service.FocalPoint{X: 0.3, Y: 0.7}}
+		second := &Request{Mode: "fill", Width: 300, Height: 200, FocalPoint: &service.FocalPoint{X: 0.7, Y: 0.3}}
+		require.NotEqual(t, first.CacheKey(), second.CacheKey())
+	})
+
+	t.Run("different jpeg qualities have different cache keys", func(t *testing.T) {
+		first := &Request{Mode: "fill", Width: 300, Height: 200, Format: "jpeg", Quality: 60}
+		second := &Request{Mode: "fill", Width: 300, Height: 200, Format: "jpeg", Quality: 80}
+		require.NotEqual(t, first.CacheKey(), second.CacheKey())
+	})
+}
+
+func TestRequestValidate(t *testing.T) {
+	t.Run("validate request: auto height", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/fill/300/auto/localhost/image.jpg", nil)
+		r.Pattern = "/fill/"
+		req := &Request{Protocol: "http", Mode: "fill"}
+		err := req.Validate(r)
+		require.NoError(t, err)
+		require.Equal(t, 300, req.Width)
+		require.Equal(t, 0, req.Height)
+		require.Equal(t, "http://localhost/image.jpg", req.URL)
+	})
+
+	t.Run("error validate request: both sizes auto", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/fill/0/auto/localhost/image.jpg", nil)
+		r.Pattern = "/fill/"
+		req := &Request{Protocol: "http", Mode: "fill"}
+		err := req.Validate(r)
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong size: width and height are both auto")
+	})
+}
+
+func TestRequestValidateBackground(t *testing.T) {
+	t.Run("validate background: default white", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateBackground("")
+		require.NoError(t, err)
+		require.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, req.Background)
+	})
+
+	t.Run("validate background: hex color", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateBackground("ff8000")
+		require.NoError(t, err)
+		require.Equal(t, color.NRGBA{R: 255, G: 128, B: 0, A: 255}, req.Background)
+
+		err = req.validateBackground("#0f0")
+		require.NoError(t, err)
+		require.Equal(t, color.NRGBA{R: 0, G: 255, B: 0, A: 255}, req.Background)
+	})
+
+	t.Run("validate background: transparent png", func(t *testing.T) {
+		req := &Request{Format: "png"}
+		err := req.validateBackground("transparent")
+		require.NoError(t, err)
+		require.Equal(t, color.NRGBA{}, req.Background)
+	})
+
+	t.Run("error validate background: transparent jpeg", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateBackground("transparent")
+		require.Error(t, err)
+		require.EqualError(t, err, "transparent background is supported only for png")
+	})
+
+	t.Run("error validate background: wrong color", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateBackground("red")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong background: red")
+	})
+}
+
+func TestRequestValidateEnlarge(t *testing.T) {
+	t.Run("validate enlarge: default from config", func(t *testing.T) {
+		req := &Request{Enlarge: true}
+		err := req.validateEnlarge("")
+		require.NoError(t, err)
+		require.True(t, req.Enlarge)
+	})
+
+	t.Run("validate enlarge: disabled by request", func(t *testing.T) {
+		req := &Request{Enlarge: true}
+		err := req.validateEnlarge("false")
+		require.NoError(t, err)
+		require.False(t, req.Enlarge)
+	})
+
+	t.Run("error validate enlarge: wrong value", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateEnlarge("never")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong enlarge: never")
+	})
+}
+
+func TestRequestValidateFormat(t *testing.T) {
+	t.Run("validate format: default from extension", func(t *testing.T) {
+		req := &Request{Ext: ".jpg"}
+		err := req.validateFormat("", "")
+		require.NoError(t, err)
+		require.Equal(t, "jpeg", req.Format)
+	})
+
+	t.Run("validate format: png for webp and svg sources", func(t *testing.T) {
+		for _, ext := range []string{".webp", ".svg"} {
+			req := &Request{Ext: ext}
+			err := req.validateFormat("", "")
+			require.NoError(t, err)
+			require.Equal(t, "png", req.Format)
+		}
+	})
+
+	t.Run("validate format: requested format", func(t *testing.T) {
+		for format, expected := range map[string]string{"PNG": "png", "tif": "tiff", "bmp": "bmp", "gif": "gif"} {
+			req := &Request{Ext: ".jpg"}
+			err := req.validateFormat(format, "")
+			require.NoError(t, err)
+			require.Equal(t, expected, req.Format)
+		}
+	})
+
+	t.Run("validate format: auto by accept header", func(t *testing.T) {
+		req := &Request{Ext: ".jpg"}
+		err := req.validateFormat("auto", "image/png,image/*;q=0.8")
+		require.NoError(t, err)
+		require.Equal(t, "png", req.Format)
+		require.True(t, req.AutoFormat)
+	})
+
+	t.Run("error validate format: wrong format", func(t *testing.T) {
+		req := &Request{Ext: ".jpg"}
+		err := req.validateFormat("ico", "")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong format: ico")
+	})
+}
+
+func TestRequestValidateQuality(t *testing.T) {
+	t.Run("validate quality: default from config", func(t *testing.T) {
+		req := &Request{Quality: 95}
+		err := req.validateQuality("")
+		require.NoError(t, err)
+		require.Equal(t, 95, req.Quality)
+	})
+
+	t.Run("validate quality: requested quality", func(t *testing.T) {
+		req := &Request{Quality: 95}
+		err := req.validateQuality("60")
+		require.NoError(t, err)
+		require.Equal(t, 60, req.Quality)
+	})
+
+	for _, quality := range []string{"0", "101", "best"} {
+		t.Run("error validate quality: "+quality, func(t *testing.T) {
+			req := &Request{Quality: 95}
+			err := req.validateQuality(quality)
+			require.Error(t, err)
+			require.EqualError(t, err, "wrong quality: "+quality)
+		})
+	}
+}
+
+func TestRequestValidateFilter(t *testing.T) {
+	t.Run("validate filter: default from config", func(t *testing.T) {
+		req := &Request{Filter: "lanczos"}
+		err := req.validateFilter("")
+		require.NoError(t, err)
+		require.Equal(t, "lanczos", req.Filter)
+	})
+
+	t.Run("validate filter: requested filter", func(t *testing.T) {
+		for _, filter := range []string{"nearest", "box", "linear", "CatmullRom", "lanczos"} {
+			req := &Request{Filter: "lanczos"}
+			err := req.validateFilter(filter)
+			require.NoError(t, err)
+			require.Equal(t, strings.ToLower(filter), req.Filter)
+		}
+	})
+
+	t.Run("error validate filter: wrong filter", func(t *testing.T) {
+		req := &Request{Filter: "lanczos"}
+		err := req.validateFilter("bicubic")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong filter: bicubic")
+	})
+}
+
+func TestRequestValidateAdjustments(t *testing.T) {
+	t.Run("validate adjustments: ordered operations", func(t *testing.T) {
+		req := &Request{}
+		query := url.Values{"saturation": {"50"}, "blur": {"1.5"}, "brightness": {"-10"}}
+		err := req.validateAdjustments(query)
+		require.NoError(t, err)
+		require.Len(t, req.Adjustments, 3)
+		require.Equal(t, "blur:1.5", req.Adjustments[0].String())
+		require.Equal(t, "brightness:-10", req.Adjustments[1].String())
+		require.Equal(t, "saturation:50", req.Adjustments[2].String())
+	})
+
+	t.Run("error validate adjustments: out of bounds", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateAdjustments(url.Values{"contrast": {"200"}})
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong contrast: 200")
+	})
+}
+
+func TestRequestValidateOrientation(t *testing.T) {
+	t.Run("validate rotate: correct rotate", func(t *testing.T) {
+		for _, rotate := range []int{0, 90, 180, 270} {
+			req := &Request{}
+			err := req.validateRotate(strconv.Itoa(rotate))
+			require.NoError(t, err)
+			require.Equal(t, rotate, req.Rotate)
+		}
+	})
+
+	t.Run("error validate rotate: wrong rotate", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateRotate("45")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong rotate: 45")
+	})
+
+	t.Run("validate flip: correct flip", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateFlip("H")
+		require.NoError(t, err)
+		require.Equal(t, "h", req.Flip)
+	})
+
+	t.Run("error validate flip: wrong flip", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateFlip("x")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong flip: x")
+	})
+
+	t.Run("rotated variants have different cache keys", func(t *testing.T) {
+		first := &Request{Mode: "fill", Width: 300, Height: 200, Rotate: 90}
+		second := &Request{Mode: "fill", Width: 300, Height: 200, Rotate: 270}
+		require.NotEqual(t, first.CacheKey(), second.CacheKey())
+	})
+}
+
+func TestRequestValidateEffects(t *testing.T) {
+	t.Run("validate effects: list of effects", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateEffects("Sepia,invert")
+		require.NoError(t, err)
+		require.Len(t, req.Adjustments, 2)
+		require.Equal(t, "sepia", req.Adjustments[0].String())
+		require.Equal(t, "invert", req.Adjustments[1].String())
+	})
+
+	t.Run("error validate effects: wrong effect", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateEffects("grayscale,blur")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong effect: blur")
+	})
+}
+
+func TestRequestValidateWatermark(t *testing.T) {
+	watermark := config.Watermark{Path: "watermark.png", Position: "bottom-right", Opacity: 0.5, Margin: 10}
+
+	t.Run("validate watermark: configured position", func(t *testing.T) {
+		req := &Request{WatermarkConfig: watermark}
+		err := req.validateWatermark("true")
+		require.NoError(t, err)
+		require.Equal(t, &service.Watermark{Path: "watermark.png", Position: "bottom-right", Opacity: 0.5, Margin: 10},
+			req.Watermark)
+	})
+
+	t.Run("validate watermark: requested position", func(t *testing.T) {
+		req := &Request{WatermarkConfig: watermark}
+		err := req.validateWatermark("Top-Left")
+		require.NoError(t, err)
+		require.Equal(t, "top-left", req.Watermark.Position)
+	})
+
+	t.Run("validate watermark: disabled", func(t *testing.T) {
+		req := &Request{WatermarkConfig: watermark}
+		err := req.validateWatermark("false")
+		require.NoError(t, err)
+		require.Nil(t, req.Watermark)
+	})
+
+	t.Run("error validate watermark: wrong position", func(t *testing.T) {
+		req := &Request{WatermarkConfig: watermark}
+		err := req.validateWatermark("smart")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong watermark: smart")
+	})
+
+	t.Run("error validate watermark: not configured", func(t *testing.T) {
+		req := &Request{WatermarkConfig: config.Watermark{Position: "bottom-right"}}
+		err := req.validateWatermark("true")
+		require.Error(t, err)
+		require.EqualError(t, err, "watermark is not configured")
+	})
+
+	t.Run("validate watermark: cache key", func(t *testing.T) {
+		req := &Request{WatermarkConfig: watermark}
+		key := req.CacheKey()
+		err := req.validateWatermark("true")
+		require.NoError(t, err)
+		require.NotEqual(t, key, req.CacheKey())
+		require.Contains(t, req.CacheKey(), "/wm:bottom-right,0.5,10/")
+	})
+}
+
+func TestRequestValidateMask(t *testing.T) {
+	t.Run("validate mask: circle forces png", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/fill/64/64/localhost/image.jpg?mask=Circle", nil)
+		r.Pattern = "/fill/"
+		req := &Request{Protocol: "http", Mode: "fill"}
+		err := req.Validate(r)
+		require.NoError(t, err)
+		require.Equal(t, "circle", req.Mask)
+		require.Equal(t, "png", req.Format)
+	})
+
+	t.Run("validate mask: radius", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateMask("12", "")
+		require.NoError(t, err)
+		require.Equal(t, 12, req.Radius)
+		require.Equal(t, "png", req.Format)
+	})
+
+	t.Run("validate mask: without mask", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateMask("0", "")
+		require.NoError(t, err)
+		require.Equal(t, "jpeg", req.Format)
+	})
+
+	t.Run("error validate mask: wrong radius", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateMask("-5", "")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong radius: -5")
+	})
+
+	t.Run("error validate mask: wrong mask", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateMask("", "star")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong mask: star")
+	})
+}
+
+func TestRequestValidateTrim(t *testing.T) {
+	t.Run("validate trim: tolerance", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateTrim("10")
+		require.NoError(t, err)
+		require.Len(t, req.Preprocessing, 1)
+		require.Equal(t, "trim:10", req.Preprocessing[0].String())
+	})
+
+	t.Run("error validate trim: wrong tolerance", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateTrim("300")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong trim: 300")
+	})
+}
+
+func TestRequestValidateDecoration(t *testing.T) {
+	t.Run("validate decoration: border", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateBorder("4", "#fff")
+		require.NoError(t, err)
+		require.Equal(t, &service.Border{Width: 4, Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}, req.Border)
+	})
+
+	t.Run("validate decoration: black border by default", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateBorder("2", "")
+		require.NoError(t, err)
+		require.Equal(t, color.NRGBA{A: 255}, req.Border.Color)
+	})
+
+	t.Run("validate decoration: shadow forces png", func(t *testing.T) {
+		req := &Request{Format: "jpeg"}
+		err := req.validateShadow("4.5")
+		require.NoError(t, err)
+		require.Equal(t, 4.5, req.Shadow)
+		require.Equal(t, "png", req.Format)
+	})
+
+	t.Run("error validate decoration: wrong border", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateBorder("0", "")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong border: 0")
+	})
+
+	t.Run("error validate decoration: wrong border color", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateBorder("2", "blue")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong border color: blue")
+	})
+
+	t.Run("error validate decoration: wrong shadow", func(t *testing.T) {
+		req := &Request{}
+		err := req.validateShadow("NaN")
+		require.Error(t, err)
+		require.EqualError(t, err, "wrong shadow: NaN")
+	})
+}
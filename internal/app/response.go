@@ -4,8 +4,18 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"path/filepath"
+	"strings"
 )
 
+var contentTypes = map[string]string{
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".bmp":  "image/bmp",
+	".tiff": "image/tiff",
+}
+
 type Response struct {
 	Data  interface{} `json:"data"`
 	Error struct {
@@ -29,5 +39,8 @@ func (resp *Response) WriteError(w http.ResponseWriter, err error, statusCode in
 }
 
 func (resp *Response) WriteImage(w http.ResponseWriter, r *http.Request, filename string) {
+	if contentType, ok := contentTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		w.Header().Set("Content-Type", contentType)
+	}
 	http.ServeFile(w, r, filename)
 }
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriteImage(t *testing.T) {
+	dir := t.TempDir()
+	for ext, contentType := range map[string]string{
+		".jpeg": "image/jpeg",
+		".png":  "image/png",
+		".gif":  "image/gif",
+		".bmp":  "image/bmp",
+		".tiff": "image/tiff",
+	} {
+		t.Run("content type for "+ext, func(t *testing.T) {
+			filename := filepath.Join(dir, "image"+ext)
+			err := os.WriteFile(filename, []byte("image"), 0o600)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			resp := &Response{}
+			resp.WriteImage(w, httptest.NewRequest(http.MethodGet, "/fill/1/1/image"+ext, nil), filename)
+			require.Equal(t, contentType, w.Header().Get("Content-Type"))
+		})
+	}
+}
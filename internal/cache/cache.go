@@ -56,7 +56,7 @@ func (lru *lruCache) warmingCache() error {
 	var ext string
 	for _, name := range names {
 		ext = path.Ext(name)
-		lru.Set(strings.TrimRight(name, ext), ext)
+		lru.Set(strings.TrimSuffix(name, ext), ext)
 	}
 
 	return nil
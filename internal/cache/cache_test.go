@@ -141,6 +141,19 @@ func TestCache(t *testing.T) {
 		require.Equal(t, 1000, val)
 	})
 
+	t.Run("warming cache keeps the keys", func(t *testing.T) {
+		conf.Capacity = 3
+		key := "5d41402abc4b2a76b9719d911017c59e"
+		err := os.WriteFile(filepath.Join(storage.Dir, key+".jpeg"), []byte{}, 0o600)
+		require.NoError(t, err)
+
+		c := New(conf.Cache, storage)
+
+		val, ok := c.Get(key)
+		require.True(t, ok)
+		require.Equal(t, ".jpeg", val)
+	})
+
 	err := os.RemoveAll(storage.Dir)
 	require.NoError(t, err)
 }
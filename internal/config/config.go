@@ -9,11 +9,16 @@ import (
 
 type (
 	Config struct {
-		App     `yaml:"app"`
-		HTTP    `yaml:"http"`
-		Loading `yaml:"loading"`
-		Log     `yaml:"logger"`
-		Cache   `yaml:"cache"`
+		App       `yaml:"app"`
+		HTTP      `yaml:"http"`
+		Loading   `yaml:"loading"`
+		Log       `yaml:"logger"`
+		Cache     `yaml:"cache"`
+		Resize    `yaml:"resize"`
+		Image     `yaml:"image"`
+		Watermark `yaml:"watermark"`
+		Animation `yaml:"animation"`
+		Presets   map[string]Preset `yaml:"presets"`
 	}
 
 	App struct {
@@ -37,8 +42,63 @@ type (
 	Cache struct {
 		Capacity int `env-required:"true" yaml:"capacity"`
 	}
+
+	Resize struct {
+		Enlarge *bool  `yaml:"enlarge"`
+		Filter  string `env-default:"lanczos" yaml:"filter"`
+	}
+
+	Image struct {
+		JPEGQuality     int    `env-default:"95" yaml:"jpeg_quality"`
+		PNGCompression  string `env-default:"default" yaml:"png_compression"`
+		AutoOrientation *bool  `yaml:"auto_orientation"`
+	}
+
+	Watermark struct {
+		Path     string  `yaml:"path"`
+		Position string  `env-default:"bottom-right" yaml:"position"`
+		Opacity  float64 `env-default:"0.5" yaml:"opacity"`
+		Margin   int     `yaml:"margin"`
+	}
+
+	Animation struct {
+		MaxFrames int `env-default:"100" yaml:"max_frames"`
+		MaxPixels int `env-default:"20000000" yaml:"max_pixels"`
+	}
+
+	Preset struct {
+		Mode        string  `yaml:"mode"`
+		Width       int     `yaml:"width"`
+		Height      int     `yaml:"height"`
+		Gravity     string  `yaml:"gravity"`
+		Background  string  `yaml:"background"`
+		Enlarge     *bool   `yaml:"enlarge"`
+		Filter      string  `yaml:"filter"`
+		Format      string  `yaml:"format"`
+		Quality     int     `yaml:"quality"`
+		Effect      string  `yaml:"effect"`
+		Watermark   string  `yaml:"watermark"`
+		Trim        *int    `yaml:"trim"`
+		Radius      int     `yaml:"radius"`
+		Mask        string  `yaml:"mask"`
+		Border      int     `yaml:"border"`
+		BorderColor string  `yaml:"border_color"`
+		Shadow      float64 `yaml:"shadow"`
+	}
 )
 
+// EnlargeEnabled reports whether small images are upscaled, it is on unless
+// the config turns it off.
+func (r Resize) EnlargeEnabled() bool {
+	return r.Enlarge == nil || *r.Enlarge
+}
+
+// AutoOrientationEnabled reports whether the exif orientation is applied, it
+// is on unless the config turns it off.
+func (i Image) AutoOrientationEnabled() bool {
+	return i.AutoOrientation == nil || *i.AutoOrientation
+}
+
 func New(path string) *Config {
 	cfg := &Config{}
 	err := cleanenv.ReadConfig(path, cfg)
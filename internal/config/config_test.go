@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const requiredConfig = `
+app: {name: previewer, version: 1.0.0}
+http: {host: localhost, port: 5000}
+loading: {protocol: https}
+logger: {level: INFO}
+cache: {capacity: 5}
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewEnlarge(t *testing.T) {
+	t.Run("enlarge is on by default", func(t *testing.T) {
+		conf := New(writeConfig(t, requiredConfig))
+		require.True(t, conf.Resize.EnlargeEnabled())
+	})
+
+	t.Run("enlarge is turned off", func(t *testing.T) {
+		conf := New(writeConfig(t, requiredConfig+"resize: {enlarge: false}\n"))
+		require.False(t, conf.Resize.EnlargeEnabled())
+	})
+}
+
+func TestNewAutoOrientation(t *testing.T) {
+	t.Run("auto orientation is on by default", func(t *testing.T) {
+		conf := New(writeConfig(t, requiredConfig))
+		require.True(t, conf.Image.AutoOrientationEnabled())
+	})
+
+	t.Run("auto orientation is turned off", func(t *testing.T) {
+		conf := New(writeConfig(t, requiredConfig+"image: {auto_orientation: false}\n"))
+		require.False(t, conf.Image.AutoOrientationEnabled())
+	})
+}
@@ -39,6 +39,10 @@ func New(conf config.HTTP, l *logger.Logger) *Server {
 func (s *Server) Start(application *app.App) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/fill/", application.HandleFill)
+	mux.HandleFunc("/fit/", application.HandleFit)
+	mux.HandleFunc("/pad/", application.HandlePad)
+	mux.HandleFunc("/ops/", application.HandleOps)
+	mux.HandleFunc("/p/", application.HandlePreset)
 	s.server.Handler = mux
 
 	go func() {
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2C
+	gifTrailer         = 0x3B
+	gifColorTableFlag  = 0x80
+	gifColorTableSize  = 0x07
+)
+
+// isAnimation reports whether the gif is converted into gif, only then the
+// frames of the animation can be kept.
+func isAnimation(im *Image) bool {
+	return im.Ext == FormatExt(FormatGIF) && filepath.Ext(im.ImageName) == FormatExt(FormatGIF)
+}
+
+// resizeAnimation transforms every frame of the animated gif keeping the delays,
+// disposal methods and loop count. It returns nil when the gif has a single frame
+// or exceeds the limits of frames and pixels, then the first frame is used.
+func (pr *Previewer) resizeAnimation(path string, im *Image) (*gif.GIF, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, err := gif.DecodeConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	limit := im.MaxFrames
+	if area := config.Width * config.Height; area > 0 {
+		limit = min(limit, im.MaxPixels/area)
+	}
+	frames, err := countFrames(file, max(limit, 1)+1)
+	if err != nil {
+		return nil, err
+	}
+	if frames < 2 {
+		return nil, nil
+	}
+	if frames > limit {
+		pr.logg.Warn("previewer resizeAnimation", fmt.Errorf("animation exceeds limits: %d frames of %dx%d",
+			frames, config.Width, config.Height))
+		return nil, nil
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	src, err := gif.DecodeAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// the frames are cropped equally, otherwise trimming and smart gravity
+	// would move the content between the frames.
+	frameIm := *im
+	var rect image.Rectangle
+	if len(im.Preprocessing) > 0 {
+		rect = animationTrim(src, im.Preprocessing)
+		frameIm.Preprocessing = nil
+	}
+
+	dst := &gif.GIF{Delay: src.Delay, Disposal: src.Disposal, LoopCount: src.LoopCount}
+	err = coalesce(src, func(i int, frame *image.NRGBA) error {
+		if !rect.Empty() {
+			frame = imaging.Crop(frame, rect)
+		}
+		if i == 0 && isSmartFill(&frameIm) {
+			oriented := orient(frame, &frameIm)
+			width, height := fillSize(oriented, &frameIm)
+			frameIm.FocalPoint = smartFocalPoint(oriented, width, height, resampleFilter(im.Filter))
+		}
+
+		resized, err := pr.transform(frame, &frameIm)
+		if err != nil {
+			return err
+		}
+		if i > 0 && resized.Bounds() != dst.Image[0].Bounds() {
+			size := dst.Image[0].Bounds().Size()
+			resized = imaging.Resize(resized, size.X, size.Y, resampleFilter(im.Filter))
+		}
+		dst.Image = append(dst.Image, quantize(resized))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// countFrames counts the frames of the gif skipping their data without decoding,
+// it stops after the limit, so a huge animation is not read to the end.
+func countFrames(r io.Reader, limit int) (int, error) {
+	br := bufio.NewReader(r)
+	// header and logical screen descriptor.
+	screen := make([]byte, 13)
+	if _, err := io.ReadFull(br, screen); err != nil {
+		return 0, err
+	}
+	if err := skipColorTable(br, screen[10]); err != nil {
+		return 0, err
+	}
+
+	frames := 0
+	for frames < limit {
+		block, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch block {
+		case gifExtension:
+			if _, err = br.Discard(1); err != nil {
+				return 0, err
+			}
+		case gifImageDescriptor:
+			frames++
+			descriptor := make([]byte, 9)
+			if _, err = io.ReadFull(br, descriptor); err != nil {
+				return 0, err
+			}
+			if err = skipColorTable(br, descriptor[8]); err != nil {
+				return 0, err
+			}
+			// minimum code size of lzw.
+			if _, err = br.Discard(1); err != nil {
+				return 0, err
+			}
+		case gifTrailer:
+			return frames, nil
+		default:
+			return 0, fmt.Errorf("unknown gif block: %#x", block)
+		}
+		if err = skipSubBlocks(br); err != nil {
+			return 0, err
+		}
+	}
+	return frames, nil
+}
+
+// skipColorTable skips the global or local color table described by the flags.
+func skipColorTable(br *bufio.Reader, flags byte) error {
+	if flags&gifColorTableFlag == 0 {
+		return nil
+	}
+	_, err := br.Discard(3 << (flags&gifColorTableSize + 1))
+	return err
+}
+
+// skipSubBlocks skips the data sub-blocks up to the block terminator.
+func skipSubBlocks(br *bufio.Reader) error {
+	for {
+		size, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			return nil
+		}
+		if _, err = br.Discard(int(size)); err != nil {
+			return err
+		}
+	}
+}
+
+// coalesce draws the frames of the gif one over another honoring the disposal
+// methods and passes every composed frame to the function. The frame is reused,
+// so the function must not keep it.
+func coalesce(src *gif.GIF, fn func(i int, frame *image.NRGBA) error) error {
+	canvas := image.NewNRGBA(image.Rect(0, 0, src.Config.Width, src.Config.Height))
+	for i, frame := range src.Image {
+		var previous *image.NRGBA
+		if src.Disposal[i] == gif.DisposalPrevious {
+			previous = imaging.Clone(canvas)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		if err := fn(i, canvas); err != nil {
+			return err
+		}
+
+		switch src.Disposal[i] {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return nil
+}
+
+// animationTrim returns the union of the trim rectangles of all the frames, so
+// the content of every frame is kept.
+func animationTrim(src *gif.GIF, ops []Operation) image.Rectangle {
+	var rect image.Rectangle
+	_ = coalesce(src, func(_ int, frame *image.NRGBA) error {
+		for _, op := range ops {
+			if op.Name == "trim" {
+				rect = rect.Union(trimRect(frame, int(op.Args[0])))
+			}
+		}
+		return nil
+	})
+	return rect
+}
+
+func saveAnimation(animation *gif.GIF, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = gif.EncodeAll(file, animation)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// quantize converts the frame into the palette of its most frequent colors.
+// Gif has no partial transparency, so translucent pixels become either
+// transparent or opaque.
+func quantize(img *image.NRGBA) *image.Paletted {
+	type bucket struct {
+		r, g, b, count int
+	}
+	buckets := make(map[int]*bucket)
+	opaque := imaging.Clone(img)
+	transparent := false
+	for i := 0; i < len(opaque.Pix); i += 4 {
+		p := opaque.Pix[i : i+4 : i+4]
+		if p[3] < 128 {
+			p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+			transparent = true
+			continue
+		}
+		p[3] = 255
+
+		key := int(p[0]>>3)<<10 | int(p[1]>>3)<<5 | int(p[2]>>3)
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.r, b.g, b.b, b.count = b.r+int(p[0]), b.g+int(p[1]), b.b+int(p[2]), b.count+1
+	}
+
+	sorted := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		sorted = append(sorted, b)
+	}
+	slices.SortFunc(sorted, func(a, b *bucket) int {
+		return cmp.Compare(b.count, a.count)
+	})
+
+	palette := make(color.Palette, 0, 256)
+	if transparent || len(sorted) == 0 {
+		palette = append(palette, color.NRGBA{})
+	}
+	for _, b := range sorted[:min(len(sorted), cap(palette)-len(palette))] {
+		palette = append(palette, color.NRGBA{
+			R: uint8(b.r / b.count), G: uint8(b.g / b.count), B: uint8(b.b / b.count), A: 255,
+		})
+	}
+
+	paletted := image.NewPaletted(opaque.Bounds(), palette)
+	draw.FloydSteinberg.Draw(paletted, opaque.Bounds(), opaque, opaque.Bounds().Min)
+	return paletted
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AndreiGoStorm/previewer/internal/logger"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewerResizeAnimation(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	palette := color.Palette{color.NRGBA{}, red, blue}
+	frame := func(rect image.Rectangle, index uint8) *image.Paletted {
+		img := image.NewPaletted(rect, palette)
+		for i := range img.Pix {
+			img.Pix[i] = index
+		}
+		return img
+	}
+	// the second frame updates only the left half of the first one.
+	animation := &gif.GIF{
+		Image:     []*image.Paletted{frame(image.Rect(0, 0, 40, 20), 1), frame(image.Rect(0, 0, 20, 20), 2)},
+		Delay:     []int{10, 20},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground},
+		LoopCount: 3,
+	}
+
+	previewer := New(logger.New("INFO"))
+	defer os.RemoveAll(previewer.Storage.Dir)
+	require.NoError(t, saveAnimation(animation, filepath.Join(previewer.Storage.Dir, "loaded.gif")))
+
+	im := &Image{
+		Mode:            ModeFill,
+		Width:           20,
+		Height:          10,
+		Ext:             ".gif",
+		ImageName:       "animation.gif",
+		LoadedImageName: "loaded.gif",
+		MaxFrames:       10,
+		MaxPixels:       10000,
+	}
+	t.Run("all frames are resized", func(t *testing.T) {
+		require.NoError(t, previewer.Resize(im))
+
+		file, err := os.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+		require.NoError(t, err)
+		defer file.Close()
+		resized, err := gif.DecodeAll(file)
+		require.NoError(t, err)
+
+		require.Len(t, resized.Image, 2)
+		require.Equal(t, []int{10, 20}, resized.Delay)
+		require.Equal(t, []byte{gif.DisposalNone, gif.DisposalBackground}, resized.Disposal)
+		require.Equal(t, 3, resized.LoopCount)
+		for _, img := range resized.Image {
+			require.Equal(t, image.Rect(0, 0, 20, 10), img.Bounds())
+		}
+		// colors of the palette are averaged, so they may differ slightly.
+		left := color.NRGBAModel.Convert(resized.Image[1].At(2, 5)).(color.NRGBA)
+		right := color.NRGBAModel.Convert(resized.Image[1].At(17, 5)).(color.NRGBA)
+		require.InDelta(t, blue.B, left.B, 2)
+		require.InDelta(t, red.R, right.R, 2)
+		require.Zero(t, left.R)
+		require.Zero(t, right.B)
+	})
+
+	t.Run("limits use the first frame", func(t *testing.T) {
+		limited := *im
+		limited.ImageName = "limited.gif"
+		limited.MaxFrames = 1
+		require.NoError(t, previewer.Resize(&limited))
+
+		file, err := os.Open(filepath.Join(previewer.Storage.Dir, limited.ImageName))
+		require.NoError(t, err)
+		defer file.Close()
+		resized, err := gif.DecodeAll(file)
+		require.NoError(t, err)
+		require.Len(t, resized.Image, 1)
+	})
+}
+
+func TestPreviewerResizeAnimationStable(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.NRGBA{A: 255}
+	palette := color.Palette{white, black}
+	// every frame has a black square, it moves from the left to the right.
+	frame := func(square image.Rectangle) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, 60, 20), palette)
+		for y := square.Min.Y; y < square.Max.Y; y++ {
+			for x := square.Min.X; x < square.Max.X; x++ {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+		return img
+	}
+	animation := &gif.GIF{
+		Image:    []*image.Paletted{frame(image.Rect(6, 6, 14, 14)), frame(image.Rect(46, 6, 54, 14))},
+		Delay:    []int{10, 10},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone},
+	}
+
+	previewer := New(logger.New("INFO"))
+	defer os.RemoveAll(previewer.Storage.Dir)
+	require.NoError(t, saveAnimation(animation, filepath.Join(previewer.Storage.Dir, "loaded.gif")))
+
+	resize := func(t *testing.T, im *Image) *gif.GIF {
+		t.Helper()
+		im.Ext = ".gif"
+		im.LoadedImageName = "loaded.gif"
+		im.MaxFrames = 10
+		im.MaxPixels = 10000
+		require.NoError(t, previewer.Resize(im))
+
+		file, err := os.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+		require.NoError(t, err)
+		defer file.Close()
+		resized, err := gif.DecodeAll(file)
+		require.NoError(t, err)
+		require.Len(t, resized.Image, 2)
+		return resized
+	}
+	gray := func(img image.Image, x, y int) uint8 {
+		return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+	}
+
+	t.Run("smart gravity crops the same window", func(t *testing.T) {
+		resized := resize(t, &Image{
+			Mode: ModeFill, Width: 20, Height: 20, Gravity: GravitySmart, ImageName: "smart.gif",
+		})
+		for _, img := range resized.Image {
+			require.Equal(t, image.Rect(0, 0, 20, 20), img.Bounds())
+		}
+		// the window is chosen by the first frame, the square leaves it later.
+		require.Less(t, gray(resized.Image[0], 5, 10), uint8(64))
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 20; x++ {
+				require.Greater(t, gray(resized.Image[1], x, y), uint8(192))
+			}
+		}
+	})
+
+	t.Run("trim uses the union of frames", func(t *testing.T) {
+		resized := resize(t, &Image{
+			Mode:          ModeOps,
+			ImageName:     "trim.gif",
+			Preprocessing: []Operation{{Name: "trim", Args: []float64{10}}},
+		})
+		for _, img := range resized.Image {
+			require.Equal(t, image.Rect(0, 0, 48, 8), img.Bounds())
+		}
+		require.Less(t, gray(resized.Image[0], 4, 4), uint8(64))
+		require.Greater(t, gray(resized.Image[0], 44, 4), uint8(192))
+		require.Greater(t, gray(resized.Image[1], 4, 4), uint8(192))
+		require.Less(t, gray(resized.Image[1], 44, 4), uint8(64))
+	})
+}
+
+func TestCountFrames(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	animation := &gif.GIF{}
+	for i := 0; i < 20; i++ {
+		animation.Image = append(animation.Image, image.NewPaletted(image.Rect(0, 0, 10, 10), palette))
+		animation.Delay = append(animation.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, animation))
+
+	t.Run("all frames", func(t *testing.T) {
+		frames, err := countFrames(bytes.NewReader(buf.Bytes()), 100)
+		require.NoError(t, err)
+		require.Equal(t, 20, frames)
+	})
+
+	t.Run("stops after the limit", func(t *testing.T) {
+		frames, err := countFrames(bytes.NewReader(buf.Bytes()), 5)
+		require.NoError(t, err)
+		require.Equal(t, 5, frames)
+	})
+
+	t.Run("limit is hit before the broken frames", func(t *testing.T) {
+		previewer := New(logger.New("INFO"))
+		defer os.RemoveAll(previewer.Storage.Dir)
+		// the last frame is cut off, so the whole animation can not be decoded.
+		broken := buf.Bytes()[:buf.Len()-10]
+		_, err := gif.DecodeAll(bytes.NewReader(broken))
+		require.Error(t, err)
+		err = os.WriteFile(filepath.Join(previewer.Storage.Dir, "loaded.gif"), broken, 0o600)
+		require.NoError(t, err)
+
+		im := &Image{
+			Mode:            ModeFill,
+			Width:           5,
+			Height:          5,
+			Ext:             ".gif",
+			ImageName:       "limited.gif",
+			LoadedImageName: "loaded.gif",
+			MaxFrames:       5,
+			MaxPixels:       10000,
+		}
+		require.NoError(t, previewer.Resize(im))
+
+		file, err := os.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+		require.NoError(t, err)
+		defer file.Close()
+		resized, err := gif.DecodeAll(file)
+		require.NoError(t, err)
+		require.Len(t, resized.Image, 1)
+	})
+}
+
+func TestQuantize(t *testing.T) {
+	img := imaging.New(4, 1, color.NRGBA{R: 10, G: 200, B: 30, A: 255})
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 100})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 200})
+
+	paletted := quantize(img)
+	require.Equal(t, color.NRGBA{}, color.NRGBAModel.Convert(paletted.At(0, 0)))
+	require.Equal(t, color.NRGBA{B: 255, A: 255}, color.NRGBAModel.Convert(paletted.At(1, 0)))
+	require.Equal(t, color.NRGBA{R: 10, G: 200, B: 30, A: 255}, color.NRGBAModel.Convert(paletted.At(3, 0)))
+	require.Len(t, paletted.Palette, 3)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Caption is a text rendered over the preview, the optional background
+// draws a box behind the text.
+type Caption struct {
+	Text       string
+	Size       float64
+	Color      color.NRGBA
+	Background *color.NRGBA
+	Position   string
+}
+
+var captionFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(gobold.TTF)
+})
+
+func ValidCaptionPosition(position string) bool {
+	_, ok := gravityAnchors[position]
+	return ok
+}
+
+// drawCaption renders the caption in the position of the image, the text is
+// padded by a quarter of the font size from the box and the box from the edges.
+func drawCaption(img image.Image, caption *Caption) (*image.NRGBA, error) {
+	f, err := captionFont()
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: caption.Size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	dst := imaging.Clone(img)
+	metrics := face.Metrics()
+	padding := int(caption.Size / 4)
+	width := font.MeasureString(face, caption.Text).Ceil() + 2*padding
+	height := (metrics.Ascent + metrics.Descent).Ceil() + 2*padding
+	box := image.Rectangle{Max: image.Pt(width, height)}
+	box = box.Add(anchorPoint(dst.Bounds(), box.Size(), caption.Position, padding))
+
+	if caption.Background != nil {
+		draw.Draw(dst, box, image.NewUniform(*caption.Background), image.Point{}, draw.Over)
+	}
+	drawer := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(caption.Color),
+		Face: face,
+		Dot:  fixed.P(box.Min.X+padding, box.Min.Y+padding+metrics.Ascent.Ceil()),
+	}
+	drawer.DrawString(caption.Text)
+	return dst, nil
+}
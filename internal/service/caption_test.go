@@ -0,0 +1,63 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawCaption(t *testing.T) {
+	black := color.NRGBA{A: 255}
+	red := color.NRGBA{R: 255, A: 255}
+	img := imaging.New(200, 100, black)
+
+	t.Run("text without background", func(t *testing.T) {
+		captioned, err := drawCaption(img, &Caption{Text: "SOLD", Size: 24, Color: red, Position: GravityCenter})
+		require.NoError(t, err)
+		require.Equal(t, img.Bounds(), captioned.Bounds())
+		require.True(t, hasColor(captioned, red))
+		require.Equal(t, black, captioned.NRGBAAt(0, 0))
+	})
+
+	t.Run("text with background box", func(t *testing.T) {
+		white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+		caption := &Caption{Text: "$ 9.99", Size: 20, Color: red, Background: &white, Position: GravityTopLeft}
+		captioned, err := drawCaption(img, caption)
+		require.NoError(t, err)
+		require.Equal(t, black, captioned.NRGBAAt(4, 4))
+		require.Equal(t, white, captioned.NRGBAAt(5, 5))
+		require.Equal(t, black, captioned.NRGBAAt(199, 99))
+	})
+}
+
+func TestAnchorPoint(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 50)
+	size := image.Pt(20, 10)
+	for _, test := range []struct {
+		gravity  string
+		expected image.Point
+	}{
+		{gravity: GravityCenter, expected: image.Pt(40, 20)},
+		{gravity: GravityTop, expected: image.Pt(40, 5)},
+		{gravity: GravityBottomLeft, expected: image.Pt(5, 35)},
+		{gravity: GravityRight, expected: image.Pt(75, 20)},
+	} {
+		t.Run(test.gravity, func(t *testing.T) {
+			require.Equal(t, test.expected, anchorPoint(bounds, size, test.gravity, 5))
+		})
+	}
+}
+
+func hasColor(img *image.NRGBA, c color.NRGBA) bool {
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if img.NRGBAAt(x, y) == c {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// shadowOpacity is the alpha of the shadow under the opaque pixels of the image.
+const shadowOpacity = 0.5
+
+// Border is a solid frame drawn around the image.
+type Border struct {
+	Width int
+	Color color.NRGBA
+}
+
+// addBorder extends the image by the width of the border on every side, the
+// border of the image with rounded corners is rounded by the radius grown by
+// the width, so it follows the shape of the mask.
+func addBorder(img image.Image, border *Border, radius float64) *image.NRGBA {
+	bounds := img.Bounds()
+	canvas := imaging.New(bounds.Dx()+2*border.Width, bounds.Dy()+2*border.Width, border.Color)
+	if radius > 0 {
+		roundCorners(canvas, radius+float64(border.Width))
+	}
+	return imaging.Overlay(canvas, img, image.Pt(border.Width, border.Width), 1)
+}
+
+// dropShadow places the image on the transparent canvas over its blurred
+// shadow, the shadow is shifted down and right by the half of the blur sigma.
+// The canvas is extended on every side so that the blur fits into it.
+func dropShadow(img image.Image, sigma float64) *image.NRGBA {
+	bounds := img.Bounds()
+	margin := int(math.Ceil(3 * sigma))
+	offset := max(1, int(math.Round(sigma/2)))
+
+	mask := image.NewNRGBA(image.Rect(0, 0, bounds.Dx()+2*margin, bounds.Dy()+2*margin))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			_, _, _, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			alpha := uint8(math.Round(float64(a>>8) * shadowOpacity))
+			mask.SetNRGBA(margin+offset+x, margin+offset+y, color.NRGBA{A: alpha})
+		}
+	}
+	shadow := imaging.Blur(mask, sigma)
+	return imaging.Overlay(shadow, img, image.Pt(margin, margin), 1)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddBorder(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	img := imaging.New(20, 10, red)
+
+	bordered := addBorder(img, &Border{Width: 3, Color: blue}, 0)
+	require.Equal(t, image.Rect(0, 0, 26, 16), bordered.Bounds())
+	require.Equal(t, blue, bordered.NRGBAAt(0, 0))
+	require.Equal(t, blue, bordered.NRGBAAt(25, 15))
+	require.Equal(t, red, bordered.NRGBAAt(3, 3))
+	require.Equal(t, red, bordered.NRGBAAt(22, 12))
+}
+
+func TestTransformMaskedBorder(t *testing.T) {
+	blue := color.NRGBA{B: 255, A: 255}
+	img := imaging.New(100, 60, color.NRGBA{R: 255, A: 255})
+	border := &Border{Width: 4, Color: blue}
+
+	t.Run("circle", func(t *testing.T) {
+		im := &Image{Mode: ModeOps, Mask: MaskCircle, Border: border}
+		bordered, err := (&Previewer{}).transform(img, im)
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 68, 68), bordered.Bounds())
+		require.Equal(t, uint8(0), bordered.NRGBAAt(0, 0).A)
+		require.Equal(t, uint8(0), bordered.NRGBAAt(67, 67).A)
+		require.Equal(t, blue, bordered.NRGBAAt(34, 1))
+		require.Equal(t, blue, bordered.NRGBAAt(1, 34))
+	})
+
+	t.Run("rounded corners", func(t *testing.T) {
+		im := &Image{Mode: ModeOps, Radius: 20, Border: border}
+		bordered, err := (&Previewer{}).transform(img, im)
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, 108, 68), bordered.Bounds())
+		require.Equal(t, uint8(0), bordered.NRGBAAt(0, 0).A)
+		require.Equal(t, blue, bordered.NRGBAAt(54, 1))
+	})
+}
+
+func TestDropShadow(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	img := imaging.New(20, 10, red)
+
+	shadowed := dropShadow(img, 2)
+	require.Equal(t, image.Rect(0, 0, 32, 22), shadowed.Bounds())
+	require.Equal(t, red, shadowed.NRGBAAt(6, 6))
+	require.Equal(t, uint8(0), shadowed.NRGBAAt(0, 0).A)
+
+	shadow := shadowed.NRGBAAt(26, 14)
+	require.Equal(t, uint8(0), shadow.R)
+	require.Greater(t, shadow.A, uint8(0))
+	require.Less(t, shadow.A, uint8(128))
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	EffectGrayscale = "grayscale"
+	EffectSepia     = "sepia"
+	EffectInvert    = "invert"
+)
+
+func ValidEffect(effect string) bool {
+	return effect == EffectGrayscale || effect == EffectSepia || effect == EffectInvert
+}
+
+// sepia tones the image with the classic sepia color matrix.
+func sepia(img image.Image) *image.NRGBA {
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		return color.NRGBA{
+			R: clampUint8(0.393*r + 0.769*g + 0.189*b),
+			G: clampUint8(0.349*r + 0.686*g + 0.168*b),
+			B: clampUint8(0.272*r + 0.534*g + 0.131*b),
+			A: c.A,
+		}
+	})
+}
+
+func clampUint8(v float64) uint8 {
+	return uint8(math.Min(255, math.Round(v)))
+}
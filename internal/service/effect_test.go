@@ -0,0 +1,36 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSepia(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 200})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	toned := sepia(img)
+	require.Equal(t, color.NRGBA{R: 135, G: 120, B: 94, A: 200}, toned.NRGBAAt(0, 0))
+	require.Equal(t, color.NRGBA{R: 255, G: 255, B: 239, A: 255}, toned.NRGBAAt(1, 0))
+}
+
+func TestApplyEffects(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 50, B: 0, A: 255})
+
+	invert, err := NewOperation(EffectInvert, "")
+	require.NoError(t, err)
+	grayscale, err := NewOperation(EffectGrayscale, "")
+	require.NoError(t, err)
+
+	inverted := applyOperations(img, []Operation{invert}, resampleFilter(""))
+	require.Equal(t, color.NRGBA{R: 55, G: 205, B: 255, A: 255}, inverted.NRGBAAt(0, 0))
+
+	gray := applyOperations(img, []Operation{grayscale}, resampleFilter("")).NRGBAAt(0, 0)
+	require.Equal(t, gray.R, gray.G)
+	require.Equal(t, gray.G, gray.B)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"image/png"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/bmp"  // register the bmp decoder.
+	_ "golang.org/x/image/tiff" // register the tiff decoder.
+	_ "golang.org/x/image/webp" // register the webp decoder.
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatBMP  = "bmp"
+	FormatTIFF = "tiff"
+	FormatWebP = "webp"
+)
+
+var pngCompressionLevels = map[string]png.CompressionLevel{
+	"default":          png.DefaultCompression,
+	"no_compression":   png.NoCompression,
+	"best_speed":       png.BestSpeed,
+	"best_compression": png.BestCompression,
+}
+
+var formatAliases = map[string]string{
+	"jpg":      FormatJPEG,
+	FormatJPEG: FormatJPEG,
+	FormatPNG:  FormatPNG,
+	FormatGIF:  FormatGIF,
+	FormatBMP:  FormatBMP,
+	"tif":      FormatTIFF,
+	FormatTIFF: FormatTIFF,
+}
+
+func ValidCompression(compression string) bool {
+	_, ok := pngCompressionLevels[compression]
+	return ok
+}
+
+// sourceExts are the extensions of the images which can be decoded, svg
+// images are rasterized.
+var sourceExts = map[string]bool{
+	"jpg":      true,
+	FormatJPEG: true,
+	FormatPNG:  true,
+	FormatGIF:  true,
+	FormatBMP:  true,
+	"tif":      true,
+	FormatTIFF: true,
+	FormatWebP: true,
+	FormatSVG:  true,
+}
+
+func ValidSourceExt(ext string) bool {
+	return sourceExts[strings.ToLower(strings.TrimPrefix(ext, "."))]
+}
+
+// ParseFormat returns the canonical name of the output format by its name or extension.
+func ParseFormat(name string) (string, bool) {
+	format, ok := formatAliases[strings.ToLower(strings.TrimPrefix(name, "."))]
+	return format, ok
+}
+
+func FormatExt(format string) string {
+	return "." + format
+}
+
+// encodeOptions returns the encoder settings of the image, zero quality and
+// unknown compression leave the encoder defaults.
+func encodeOptions(im *Image) []imaging.EncodeOption {
+	options := []imaging.EncodeOption{imaging.PNGCompressionLevel(pngCompressionLevels[im.Compression])}
+	if im.Quality > 0 {
+		options = append(options, imaging.JPEGQuality(im.Quality))
+	}
+	return options
+}
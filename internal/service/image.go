@@ -1,10 +1,47 @@
 package service
 
+import "image/color"
+
+const (
+	ModeFill = "fill"
+	ModeFit  = "fit"
+	ModePad  = "pad"
+	ModeOps  = "ops"
+)
+
 type Image struct {
+	Mode            string
 	Width           int
 	Height          int
+	Gravity         string
+	FocalPoint      *FocalPoint
+	Background      color.NRGBA
+	AutoOrientation bool
+	Rotate          int
+	Flip            string
+	Enlarge         bool
+	Filter          string
+	Operations      []Operation
+	Preprocessing   []Operation
+	Adjustments     []Operation
+	Caption         *Caption
+	Radius          int
+	Mask            string
+	Border          *Border
+	Shadow          float64
+	Watermark       *Watermark
+	MaxFrames       int
+	MaxPixels       int
+	Quality         int
+	Compression     string
 	URL             string
 	Ext             string
 	ImageName       string
 	LoadedImageName string
 }
+
+// FocalPoint is a point of interest in coordinates relative to the image size.
+type FocalPoint struct {
+	X float64
+	Y float64
+}
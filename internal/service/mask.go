@@ -0,0 +1,59 @@
+package service
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const MaskCircle = "circle"
+
+func ValidMask(mask string) bool {
+	return mask == "" || mask == MaskCircle
+}
+
+// applyMask makes the corners of the image transparent, the circle mask crops
+// the image to the centered square first.
+func applyMask(img image.Image, im *Image) *image.NRGBA {
+	dst := imaging.Clone(img)
+	if im.Mask == MaskCircle {
+		size := min(dst.Bounds().Dx(), dst.Bounds().Dy())
+		dst = imaging.CropCenter(dst, size, size)
+	}
+	roundCorners(dst, maskRadius(dst, im))
+	return dst
+}
+
+// maskRadius returns the radius of the corners of the masked image, it is
+// limited by the half of the smaller side.
+func maskRadius(img image.Image, im *Image) float64 {
+	w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	if im.Mask == MaskCircle {
+		return min(w, h) / 2
+	}
+	return min(float64(im.Radius), w/2, h/2)
+}
+
+// roundCorners scales the alpha of the pixels by their coverage of the rounded
+// rectangle, so the edge of the corners is antialiased.
+func roundCorners(img *image.NRGBA, radius float64) {
+	w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	radius = min(radius, w/2, h/2)
+	if radius <= 0 {
+		return
+	}
+
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			dx := px - max(radius, min(px, w-radius))
+			dy := py - max(radius, min(py, h-radius))
+			coverage := max(0, min(radius-math.Hypot(dx, dy)+0.5, 1))
+			if coverage < 1 {
+				i := img.PixOffset(x, y) + 3
+				img.Pix[i] = uint8(math.Round(float64(img.Pix[i]) * coverage))
+			}
+		}
+	}
+}
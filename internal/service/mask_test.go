@@ -0,0 +1,42 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMask(t *testing.T) {
+	img := imaging.New(100, 60, color.NRGBA{R: 255, A: 255})
+
+	t.Run("rounded corners", func(t *testing.T) {
+		masked := applyMask(img, &Image{Radius: 20})
+		require.Equal(t, img.Bounds(), masked.Bounds())
+		for _, corner := range []image.Point{{0, 0}, {99, 0}, {0, 59}, {99, 59}} {
+			require.Equal(t, uint8(0), masked.NRGBAAt(corner.X, corner.Y).A)
+		}
+		require.Equal(t, uint8(255), masked.NRGBAAt(20, 0).A)
+		require.Equal(t, uint8(255), masked.NRGBAAt(0, 30).A)
+		require.Equal(t, uint8(255), masked.NRGBAAt(50, 30).A)
+	})
+
+	t.Run("circle", func(t *testing.T) {
+		masked := applyMask(img, &Image{Mask: MaskCircle})
+		require.Equal(t, image.Rect(0, 0, 60, 60), masked.Bounds())
+		require.Equal(t, uint8(0), masked.NRGBAAt(5, 5).A)
+		require.Equal(t, uint8(255), masked.NRGBAAt(30, 1).A)
+		require.Equal(t, uint8(255), masked.NRGBAAt(30, 30).A)
+		edge := masked.NRGBAAt(8, 8).A
+		require.Greater(t, edge, uint8(0))
+		require.Less(t, edge, uint8(255))
+	})
+
+	t.Run("radius is limited by the size", func(t *testing.T) {
+		masked := applyMask(img, &Image{Radius: 1000})
+		require.Equal(t, uint8(255), masked.NRGBAAt(50, 0).A)
+		require.Equal(t, uint8(0), masked.NRGBAAt(0, 0).A)
+	})
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+const maxSize = 9999
+
+// Operation is a single step of the operation chain with parsed arguments.
+type Operation struct {
+	Name string
+	Args []float64
+}
+
+// String returns the canonical form of the operation, it is used for cache keys.
+func (op Operation) String() string {
+	if len(op.Args) == 0 {
+		return op.Name
+	}
+
+	args := make([]string, len(op.Args))
+	for i, arg := range op.Args {
+		args[i] = strconv.FormatFloat(arg, 'f', -1, 64)
+	}
+	return op.Name + ":" + strings.Join(args, operations[op.Name].separator)
+}
+
+type operationFunc func(img image.Image, args []float64, filter imaging.ResampleFilter) *image.NRGBA
+
+type operation struct {
+	separator string
+	bounds    [][2]float64
+	integer   bool
+	validate  func(args []float64) bool
+	apply     operationFunc
+}
+
+var sizeBounds = [][2]float64{{1, maxSize}, {1, maxSize}}
+
+var operations = map[string]operation{
+	"resize": {
+		separator: "x",
+		bounds:    [][2]float64{{0, maxSize}, {0, maxSize}},
+		integer:   true,
+		validate: func(args []float64) bool {
+			return args[0] > 0 || args[1] > 0
+		},
+		apply: func(img image.Image, args []float64, filter imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Resize(img, int(args[0]), int(args[1]), filter)
+		},
+	},
+	"fill": {
+		separator: "x",
+		bounds:    sizeBounds,
+		integer:   true,
+		apply: func(img image.Image, args []float64, filter imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Fill(img, int(args[0]), int(args[1]), imaging.Center, filter)
+		},
+	},
+	"fit": {
+		separator: "x",
+		bounds:    sizeBounds,
+		integer:   true,
+		apply: func(img image.Image, args []float64, filter imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Fit(img, int(args[0]), int(args[1]), filter)
+		},
+	},
+	"crop": {
+		separator: "x",
+		bounds:    sizeBounds,
+		integer:   true,
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.CropCenter(img, int(args[0]), int(args[1]))
+		},
+	},
+	"trim": {
+		bounds:  [][2]float64{{0, 255}},
+		integer: true,
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return trim(img, int(args[0]))
+		},
+	},
+	"blur": {
+		bounds: [][2]float64{{0, 100}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Blur(img, args[0])
+		},
+	},
+	"sharpen": {
+		bounds: [][2]float64{{0, 100}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Sharpen(img, args[0])
+		},
+	},
+	"brightness": {
+		bounds: [][2]float64{{-100, 100}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.AdjustBrightness(img, args[0])
+		},
+	},
+	"contrast": {
+		bounds: [][2]float64{{-100, 100}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.AdjustContrast(img, args[0])
+		},
+	},
+	"gamma": {
+		bounds: [][2]float64{{0.1, 10}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.AdjustGamma(img, args[0])
+		},
+	},
+	"saturation": {
+		bounds: [][2]float64{{-100, 500}},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.AdjustSaturation(img, args[0])
+		},
+	},
+	"rotate": {
+		bounds:  [][2]float64{{90, 270}},
+		integer: true,
+		validate: func(args []float64) bool {
+			return ValidRotate(int(args[0]))
+		},
+		apply: func(img image.Image, args []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return rotate(img, int(args[0]))
+		},
+	},
+	"fliph": {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.FlipH(img)
+		},
+	},
+	"flipv": {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.FlipV(img)
+		},
+	},
+	"transpose": {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Transpose(img)
+		},
+	},
+	"transverse": {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Transverse(img)
+		},
+	},
+	EffectGrayscale: {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Grayscale(img)
+		},
+	},
+	EffectSepia: {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return sepia(img)
+		},
+	},
+	EffectInvert: {
+		apply: func(img image.Image, _ []float64, _ imaging.ResampleFilter) *image.NRGBA {
+			return imaging.Invert(img)
+		},
+	},
+}
+
+func IsOperation(name string) bool {
+	_, ok := operations[name]
+	return ok
+}
+
+// NewOperation parses and validates the arguments of the registered operation.
+func NewOperation(name, args string) (Operation, error) {
+	op, ok := operations[name]
+	if !ok {
+		return Operation{}, fmt.Errorf("unknown operation: %s", name)
+	}
+
+	var values []string
+	if args != "" {
+		separator := op.separator
+		if separator == "" {
+			separator = ","
+		}
+		values = strings.Split(args, separator)
+	}
+	if len(values) != len(op.bounds) {
+		return Operation{}, fmt.Errorf("wrong operation arguments: %s:%s", name, args)
+	}
+
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		arg, err := strconv.ParseFloat(value, 64)
+		inBounds := arg >= op.bounds[i][0] && arg <= op.bounds[i][1]
+		if err != nil || !inBounds || (op.integer && arg != math.Trunc(arg)) {
+			return Operation{}, fmt.Errorf("wrong operation arguments: %s:%s", name, args)
+		}
+		parsed[i] = arg
+	}
+	if op.validate != nil && !op.validate(parsed) {
+		return Operation{}, fmt.Errorf("wrong operation arguments: %s:%s", name, args)
+	}
+
+	return Operation{Name: name, Args: parsed}, nil
+}
+
+func applyOperations(img image.Image, ops []Operation, filter imaging.ResampleFilter) *image.NRGBA {
+	result := imaging.Clone(img)
+	for _, op := range ops {
+		result = operations[op.Name].apply(result, op.Args, filter)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOperation(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		args      string
+		canonical string
+	}{
+		{name: "resize", args: "300x200", canonical: "resize:300x200"},
+		{name: "resize", args: "300x0", canonical: "resize:300x0"},
+		{name: "fill", args: "64x64", canonical: "fill:64x64"},
+		{name: "fit", args: "1024x768", canonical: "fit:1024x768"},
+		{name: "crop", args: "50x50", canonical: "crop:50x50"},
+		{name: "blur", args: "0.50", canonical: "blur:0.5"},
+		{name: "sharpen", args: "1", canonical: "sharpen:1"},
+		{name: "brightness", args: "-20", canonical: "brightness:-20"},
+		{name: "contrast", args: "15.5", canonical: "contrast:15.5"},
+		{name: "gamma", args: "0.8", canonical: "gamma:0.8"},
+		{name: "saturation", args: "250", canonical: "saturation:250"},
+		{name: "rotate", args: "90", canonical: "rotate:90"},
+		{name: "fliph", args: "", canonical: "fliph"},
+		{name: "flipv", args: "", canonical: "flipv"},
+		{name: "transpose", args: "", canonical: "transpose"},
+		{name: "transverse", args: "", canonical: "transverse"},
+		{name: "grayscale", args: "", canonical: "grayscale"},
+		{name: "sepia", args: "", canonical: "sepia"},
+		{name: "invert", args: "", canonical: "invert"},
+	} {
+		t.Run("new operation "+test.canonical, func(t *testing.T) {
+			op, err := NewOperation(test.name, test.args)
+			require.NoError(t, err)
+			require.Equal(t, test.canonical, op.String())
+		})
+	}
+
+	for _, test := range []struct {
+		name string
+		args string
+	}{
+		{name: "resize", args: "0x0"},
+		{name: "resize", args: "300x200.5"},
+		{name: "fill", args: "0x100"},
+		{name: "fit", args: "100"},
+		{name: "blur", args: "-1"},
+		{name: "blur", args: "1,2"},
+		{name: "brightness", args: "101"},
+		{name: "contrast", args: "-150"},
+		{name: "gamma", args: "0"},
+		{name: "saturation", args: "600"},
+		{name: "rotate", args: "45"},
+		{name: "rotate", args: "120"},
+		{name: "grayscale", args: "1"},
+	} {
+		t.Run("error new operation "+test.name+":"+test.args, func(t *testing.T) {
+			_, err := NewOperation(test.name, test.args)
+			require.Error(t, err)
+			require.EqualError(t, err, "wrong operation arguments: "+test.name+":"+test.args)
+		})
+	}
+
+	t.Run("error new operation: unknown operation", func(t *testing.T) {
+		_, err := NewOperation("emboss", "")
+		require.Error(t, err)
+		require.EqualError(t, err, "unknown operation: emboss")
+	})
+}
+
+func TestApplyOperations(t *testing.T) {
+	img := testImage(100, 60)
+
+	resize, err := NewOperation("resize", "50x0")
+	require.NoError(t, err)
+	crop, err := NewOperation("crop", "20x10")
+	require.NoError(t, err)
+	grayscale, err := NewOperation("grayscale", "")
+	require.NoError(t, err)
+
+	result := applyOperations(img, []Operation{resize, crop, grayscale}, imaging.Lanczos)
+	require.Equal(t, 20, result.Bounds().Dx())
+	require.Equal(t, 10, result.Bounds().Dy())
+	pixel := result.NRGBAAt(10, 5)
+	require.Equal(t, pixel.R, pixel.G)
+	require.Equal(t, pixel.G, pixel.B)
+}
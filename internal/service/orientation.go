@@ -0,0 +1,50 @@
+package service
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	FlipHorizontal = "h"
+	FlipVertical   = "v"
+)
+
+func ValidRotate(degrees int) bool {
+	return degrees == 0 || degrees == 90 || degrees == 180 || degrees == 270
+}
+
+func ValidFlip(flip string) bool {
+	return flip == "" || flip == FlipHorizontal || flip == FlipVertical
+}
+
+// orient rotates the image clockwise and then flips it before resizing,
+// so the requested size applies to the corrected orientation.
+func orient(img image.Image, im *Image) image.Image {
+	if im.Rotate != 0 {
+		img = rotate(img, im.Rotate)
+	}
+
+	switch im.Flip {
+	case FlipHorizontal:
+		img = imaging.FlipH(img)
+	case FlipVertical:
+		img = imaging.FlipV(img)
+	}
+	return img
+}
+
+// rotate turns the image clockwise by the right angle in degrees.
+func rotate(img image.Image, degrees int) *image.NRGBA {
+	switch degrees {
+	case 90:
+		return imaging.Rotate270(img)
+	case 180:
+		return imaging.Rotate180(img)
+	case 270:
+		return imaging.Rotate90(img)
+	default:
+		return imaging.Clone(img)
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrient(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	// a 2x1 image with the red pixel on the left.
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, red)
+	horizontal, vertical := image.Rect(0, 0, 2, 1), image.Rect(0, 0, 1, 2)
+
+	for _, test := range []struct {
+		name   string
+		im     Image
+		bounds image.Rectangle
+		red    image.Point
+	}{
+		{name: "rotate 90 clockwise", im: Image{Rotate: 90}, bounds: vertical, red: image.Pt(0, 0)},
+		{name: "rotate 180", im: Image{Rotate: 180}, bounds: horizontal, red: image.Pt(1, 0)},
+		{name: "rotate 270 clockwise", im: Image{Rotate: 270}, bounds: vertical, red: image.Pt(0, 1)},
+		{name: "flip horizontal", im: Image{Flip: FlipHorizontal}, bounds: horizontal, red: image.Pt(1, 0)},
+		{name: "flip vertical", im: Image{Flip: FlipVertical}, bounds: horizontal, red: image.Pt(0, 0)},
+		{name: "rotate and flip", im: Image{Rotate: 90, Flip: FlipVertical}, bounds: vertical, red: image.Pt(0, 1)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			oriented := orient(img, &test.im)
+			require.Equal(t, test.bounds, oriented.Bounds())
+			require.Equal(t, red, color.NRGBAModel.Convert(oriented.At(test.red.X, test.red.Y)))
+		})
+	}
+}
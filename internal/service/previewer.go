@@ -1,6 +1,7 @@
 package service
 
 import (
+	"image"
 	"net/http"
 
 	"github.com/AndreiGoStorm/previewer/internal/logger"
@@ -8,9 +9,10 @@ import (
 )
 
 type Previewer struct {
-	logg    *logger.Logger
-	loader  *Loader
-	Storage *Storage
+	logg       *logger.Logger
+	loader     *Loader
+	Storage    *Storage
+	watermarks watermarks
 }
 
 func New(logg *logger.Logger) *Previewer {
@@ -44,17 +46,71 @@ func (pr *Previewer) Preview(r *http.Request, im *Image) (err error) {
 
 func (pr *Previewer) Resize(im *Image) error {
 	path := pr.Storage.getStorageFullPath(im.LoadedImageName)
-	image, err := imaging.Open(path)
+	if isAnimation(im) {
+		animation, err := pr.resizeAnimation(path, im)
+		if err != nil {
+			return err
+		}
+		if animation != nil {
+			return saveAnimation(animation, pr.Storage.getStorageFullPath(im.ImageName))
+		}
+	}
+
+	var img image.Image
+	var err error
+	if im.Ext == FormatExt(FormatSVG) {
+		img, err = rasterizeSVG(path, im)
+	} else {
+		img, err = imaging.Open(path, imaging.AutoOrientation(im.AutoOrientation))
+	}
 	if err != nil {
 		return err
 	}
 
-	resized := imaging.Resize(image, im.Width, im.Height, imaging.Lanczos)
+	resized, err := pr.transform(img, im)
+	if err != nil {
+		return err
+	}
 
 	path = pr.Storage.getStorageFullPath(im.ImageName)
-	if err = imaging.Save(resized, path); err != nil {
+	if err = imaging.Save(resized, path, encodeOptions(im)...); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// transform runs the decoded image through all the steps of the pipeline.
+func (pr *Previewer) transform(img image.Image, im *Image) (*image.NRGBA, error) {
+	if len(im.Preprocessing) > 0 {
+		img = applyOperations(img, im.Preprocessing, resampleFilter(im.Filter))
+	}
+
+	resized := resize(orient(img, im), im)
+	resized = applyOperations(resized, im.Adjustments, resampleFilter(im.Filter))
+	if im.Caption != nil {
+		var err error
+		if resized, err = drawCaption(resized, im.Caption); err != nil {
+			return nil, err
+		}
+	}
+	if im.Watermark != nil {
+		mark, err := pr.watermarks.get(im.Watermark.Path)
+		if err != nil {
+			return nil, err
+		}
+		resized = overlayWatermark(resized, mark, im.Watermark)
+	}
+	var radius float64
+	if im.Radius > 0 || im.Mask != "" {
+		resized = applyMask(resized, im)
+		radius = maskRadius(resized, im)
+	}
+	if im.Border != nil {
+		resized = addBorder(resized, im.Border, radius)
+	}
+	if im.Shadow > 0 {
+		resized = dropShadow(resized, im.Shadow)
+	}
+	return resized, nil
+}
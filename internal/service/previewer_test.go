@@ -2,12 +2,15 @@ package service
 
 import (
 	"fmt"
+	"image"
+	"image/color"
 	"os"
 	"path"
 	"path/filepath"
 	"testing"
 
 	"github.com/AndreiGoStorm/previewer/internal/logger"
+	"github.com/disintegration/imaging"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,3 +60,263 @@ func TestPreviewer(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewerResizeModes(t *testing.T) {
+	logg := logger.New("INFO")
+	for _, test := range []struct {
+		im     Image
+		width  int
+		height int
+	}{
+		{
+			im:    Image{Mode: ModeFill, Width: 30, Height: 30, Ext: ".png"},
+			width: 30, height: 30,
+		},
+		{
+			im:    Image{Mode: ModeFill, Width: 20, Height: 40, Gravity: GravityLeft, Ext: ".png"},
+			width: 20, height: 40,
+		},
+		{
+			im:    Image{Mode: ModeFit, Width: 30, Height: 30, Ext: ".png"},
+			width: 30, height: 18,
+		},
+		{
+			im:    Image{Mode: ModeFill, Width: 30, Height: 20, Filter: FilterNearest, Ext: ".png"},
+			width: 30, height: 20,
+		},
+		{
+			im:    Image{Mode: ModePad, Width: 30, Height: 30, Ext: ".png"},
+			width: 30, height: 30,
+		},
+		{
+			im:    Image{Mode: ModeFill, Width: 30, Height: 0, Ext: ".png"},
+			width: 30, height: 18,
+		},
+		{
+			im:    Image{Mode: ModeFit, Width: 0, Height: 12, Ext: ".png"},
+			width: 20, height: 12,
+		},
+	} {
+		t.Run(fmt.Sprintf("resize image mode %s %dx%d", test.im.Mode, test.width, test.height), func(t *testing.T) {
+			previewer := New(logg)
+			defer os.RemoveAll(previewer.Storage.Dir)
+
+			test.im.LoadedImageName = "image_for_resize_" + test.im.Mode + test.im.Ext
+			test.im.ImageName = "image_resized_" + test.im.Mode + test.im.Ext
+			saveTestImage(t, filepath.Join(previewer.Storage.Dir, test.im.LoadedImageName), 100, 60)
+
+			err := previewer.Resize(&test.im)
+			require.NoError(t, err)
+
+			resized, err := imaging.Open(filepath.Join(previewer.Storage.Dir, test.im.ImageName))
+			require.NoError(t, err)
+			require.Equal(t, test.width, resized.Bounds().Dx())
+			require.Equal(t, test.height, resized.Bounds().Dy())
+		})
+	}
+}
+
+func copyTestImage(t *testing.T, from, to string) {
+	t.Helper()
+	fileBytes, err := os.ReadFile(from)
+	require.NoError(t, err)
+	err = os.WriteFile(to, fileBytes, 0o600)
+	require.NoError(t, err)
+}
+
+// saveTestImage saves a generated image of the size, small images keep the
+// tests fast under the race detector.
+func saveTestImage(t *testing.T, to string, width, height int) {
+	t.Helper()
+	err := imaging.Save(testImage(width, height), to)
+	require.NoError(t, err)
+}
+
+// testImage returns an image with the color gradient of the size.
+func testImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestPad(t *testing.T) {
+	img := testImage(100, 60)
+
+	background := color.NRGBA{R: 255, G: 0, B: 0, A: 255}
+	padded := pad(img, &Image{Width: 30, Height: 30, Background: background}, imaging.Lanczos)
+	require.Equal(t, 30, padded.Bounds().Dx())
+	require.Equal(t, 30, padded.Bounds().Dy())
+	require.Equal(t, background, padded.NRGBAAt(15, 0))
+	require.Equal(t, background, padded.NRGBAAt(15, 29))
+}
+
+func TestResizeEnlarge(t *testing.T) {
+	img := testImage(20, 10)
+
+	for _, test := range []struct {
+		im     Image
+		width  int
+		height int
+	}{
+		{im: Image{Mode: ModeFill, Width: 40, Height: 40, Enlarge: true}, width: 40, height: 40},
+		{im: Image{Mode: ModeFill, Width: 40, Height: 40}, width: 10, height: 10},
+		{im: Image{Mode: ModeFill, Width: 40, Height: 0}, width: 20, height: 10},
+		{im: Image{Mode: ModeFit, Width: 40, Height: 40, Enlarge: true}, width: 40, height: 20},
+		{im: Image{Mode: ModeFit, Width: 40, Height: 40}, width: 20, height: 10},
+		{im: Image{Mode: ModePad, Width: 40, Height: 40}, width: 40, height: 40},
+	} {
+		t.Run(fmt.Sprintf("resize %s %dx%d enlarge %t", test.im.Mode, test.im.Width, test.im.Height, test.im.Enlarge),
+			func(t *testing.T) {
+				resized := resize(img, &test.im)
+				require.Equal(t, test.width, resized.Bounds().Dx())
+				require.Equal(t, test.height, resized.Bounds().Dy())
+			})
+	}
+}
+
+func TestPreviewerResizeFormat(t *testing.T) {
+	logg := logger.New("INFO")
+	for _, format := range []string{FormatJPEG, FormatGIF, FormatBMP, FormatTIFF} {
+		t.Run("convert png to "+format, func(t *testing.T) {
+			previewer := New(logg)
+			defer os.RemoveAll(previewer.Storage.Dir)
+
+			im := &Image{
+				Mode:            ModeFill,
+				Width:           30,
+				Height:          30,
+				Ext:             ".png",
+				ImageName:       "image_converted" + FormatExt(format),
+				LoadedImageName: "image_for_convert.png",
+			}
+			saveTestImage(t, filepath.Join(previewer.Storage.Dir, im.LoadedImageName), 100, 60)
+
+			err := previewer.Resize(im)
+			require.NoError(t, err)
+
+			file, err := os.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+			require.NoError(t, err)
+			defer file.Close()
+			_, decoded, err := image.DecodeConfig(file)
+			require.NoError(t, err)
+			require.Equal(t, format, decoded)
+		})
+	}
+}
+
+func TestPreviewerResizeWebP(t *testing.T) {
+	previewer := New(logger.New("INFO"))
+	defer os.RemoveAll(previewer.Storage.Dir)
+
+	im := &Image{
+		Mode:            ModeFit,
+		Width:           75,
+		Height:          75,
+		Ext:             ".webp",
+		ImageName:       "image_from_webp.png",
+		LoadedImageName: "image_for_convert.webp",
+	}
+	copyTestImage(t, "images/image.webp", filepath.Join(previewer.Storage.Dir, im.LoadedImageName))
+
+	err := previewer.Resize(im)
+	require.NoError(t, err)
+
+	img, err := imaging.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 75, 50), img.Bounds())
+}
+
+func TestPreviewerResizeQuality(t *testing.T) {
+	logg := logger.New("INFO")
+	previewer := New(logg)
+	defer os.RemoveAll(previewer.Storage.Dir)
+
+	sizes := make([]int64, 0, 2)
+	for _, quality := range []int{30, 95} {
+		im := &Image{
+			Mode:            ModeFill,
+			Width:           60,
+			Height:          60,
+			Quality:         quality,
+			Ext:             ".jpeg",
+			ImageName:       fmt.Sprintf("image_quality_%d.jpeg", quality),
+			LoadedImageName: "image_for_quality.jpeg",
+		}
+		saveTestImage(t, filepath.Join(previewer.Storage.Dir, im.LoadedImageName), 100, 60)
+
+		err := previewer.Resize(im)
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(previewer.Storage.Dir, im.ImageName))
+		require.NoError(t, err)
+		sizes = append(sizes, info.Size())
+	}
+	require.Less(t, sizes[0], sizes[1])
+}
+
+func TestPreviewerResizeAdjustments(t *testing.T) {
+	logg := logger.New("INFO")
+	previewer := New(logg)
+	defer os.RemoveAll(previewer.Storage.Dir)
+
+	brightness, err := NewOperation("brightness", "100")
+	require.NoError(t, err)
+	im := &Image{
+		Mode:            ModeFill,
+		Width:           30,
+		Height:          30,
+		Adjustments:     []Operation{brightness},
+		Ext:             ".png",
+		ImageName:       "image_adjusted.png",
+		LoadedImageName: "image_for_adjust.png",
+	}
+	saveTestImage(t, filepath.Join(previewer.Storage.Dir, im.LoadedImageName), 100, 60)
+
+	err = previewer.Resize(im)
+	require.NoError(t, err)
+
+	adjusted, err := imaging.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+	require.NoError(t, err)
+	r, g, b, _ := adjusted.At(15, 15).RGBA()
+	require.Equal(t, []uint32{0xffff, 0xffff, 0xffff}, []uint32{r, g, b})
+}
+
+func TestPreviewerResizeAutoOrientation(t *testing.T) {
+	testImageRotated := "images/image_orientation_6.jpeg"
+	logg := logger.New("INFO")
+	for _, test := range []struct {
+		autoOrientation bool
+		width           int
+		height          int
+	}{
+		{autoOrientation: true, width: 60, height: 90},
+		{autoOrientation: false, width: 60, height: 40},
+	} {
+		t.Run(fmt.Sprintf("auto orientation %t", test.autoOrientation), func(t *testing.T) {
+			previewer := New(logg)
+			defer os.RemoveAll(previewer.Storage.Dir)
+
+			im := &Image{
+				Mode:            ModeFill,
+				Width:           60,
+				AutoOrientation: test.autoOrientation,
+				Ext:             ".jpeg",
+				ImageName:       "image_oriented.jpeg",
+				LoadedImageName: "image_for_orientation.jpeg",
+			}
+			copyTestImage(t, testImageRotated, filepath.Join(previewer.Storage.Dir, im.LoadedImageName))
+
+			err := previewer.Resize(im)
+			require.NoError(t, err)
+
+			oriented, err := imaging.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+			require.NoError(t, err)
+			require.Equal(t, test.width, oriented.Bounds().Dx())
+			require.Equal(t, test.height, oriented.Bounds().Dy())
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	GravityCenter      = "center"
+	GravityTop         = "top"
+	GravityBottom      = "bottom"
+	GravityLeft        = "left"
+	GravityRight       = "right"
+	GravityTopLeft     = "top-left"
+	GravityTopRight    = "top-right"
+	GravityBottomLeft  = "bottom-left"
+	GravityBottomRight = "bottom-right"
+	GravitySmart       = "smart"
+)
+
+var gravityAnchors = map[string]imaging.Anchor{
+	GravityCenter:      imaging.Center,
+	GravityTop:         imaging.Top,
+	GravityBottom:      imaging.Bottom,
+	GravityLeft:        imaging.Left,
+	GravityRight:       imaging.Right,
+	GravityTopLeft:     imaging.TopLeft,
+	GravityTopRight:    imaging.TopRight,
+	GravityBottomLeft:  imaging.BottomLeft,
+	GravityBottomRight: imaging.BottomRight,
+}
+
+const (
+	FilterNearest    = "nearest"
+	FilterBox        = "box"
+	FilterLinear     = "linear"
+	FilterCatmullRom = "catmullrom"
+	FilterLanczos    = "lanczos"
+)
+
+var resampleFilters = map[string]imaging.ResampleFilter{
+	FilterNearest:    imaging.NearestNeighbor,
+	FilterBox:        imaging.Box,
+	FilterLinear:     imaging.Linear,
+	FilterCatmullRom: imaging.CatmullRom,
+	FilterLanczos:    imaging.Lanczos,
+}
+
+func ValidFilter(filter string) bool {
+	_, ok := resampleFilters[filter]
+	return ok
+}
+
+// resampleFilter returns the filter by name, lanczos is used by default.
+func resampleFilter(name string) imaging.ResampleFilter {
+	filter, ok := resampleFilters[name]
+	if !ok {
+		return imaging.Lanczos
+	}
+	return filter
+}
+
+func ValidGravity(gravity string) bool {
+	if gravity == GravitySmart {
+		return true
+	}
+	_, ok := gravityAnchors[gravity]
+	return ok
+}
+
+func resize(img image.Image, im *Image) *image.NRGBA {
+	filter := resampleFilter(im.Filter)
+	if im.Mode == ModeOps {
+		return applyOperations(img, im.Operations, filter)
+	}
+
+	if im.Width == 0 || im.Height == 0 {
+		width, height := im.Width, im.Height
+		if !im.Enlarge {
+			width, height = shrinkToSource(img, width, height)
+		}
+		return imaging.Resize(img, width, height, filter)
+	}
+
+	switch im.Mode {
+	case ModeFit:
+		return fit(img, im.Width, im.Height, im.Enlarge, filter)
+	case ModePad:
+		return pad(img, im, filter)
+	default:
+		return fill(img, im, filter)
+	}
+}
+
+// fill scales the image to cover the requested box and crops the overflow
+// on the side opposite to the gravity, the focal point takes precedence over gravity.
+func fill(img image.Image, im *Image, filter imaging.ResampleFilter) *image.NRGBA {
+	width, height := fillSize(img, im)
+
+	if im.FocalPoint != nil {
+		return focalFill(img, width, height, im.FocalPoint, filter)
+	}
+	if im.Gravity == GravitySmart {
+		return smartFill(img, width, height, filter)
+	}
+
+	anchor, ok := gravityAnchors[im.Gravity]
+	if !ok {
+		anchor = imaging.Center
+	}
+	return imaging.Fill(img, width, height, anchor, filter)
+}
+
+// fit scales the image to fit inside the requested box, a smaller image is
+// scaled up only when enlarging is allowed.
+func fit(img image.Image, width, height int, enlarge bool, filter imaging.ResampleFilter) *image.NRGBA {
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+	if enlarge && srcW < width && srcH < height {
+		if float64(srcW)/float64(srcH) > float64(width)/float64(height) {
+			return imaging.Resize(img, width, 0, filter)
+		}
+		return imaging.Resize(img, 0, height, filter)
+	}
+	return imaging.Fit(img, width, height, filter)
+}
+
+// pad fits the image inside the requested box and fills the remaining area
+// with the background color.
+func pad(img image.Image, im *Image, filter imaging.ResampleFilter) *image.NRGBA {
+	fitted := fit(img, im.Width, im.Height, im.Enlarge, filter)
+	canvas := imaging.New(im.Width, im.Height, im.Background)
+	return imaging.OverlayCenter(canvas, fitted, 1.0)
+}
+
+// fillSize returns the size of the box to fill, it is limited by the source
+// size unless enlarging is allowed.
+func fillSize(img image.Image, im *Image) (int, int) {
+	if !im.Enlarge {
+		return shrinkToSource(img, im.Width, im.Height)
+	}
+	return im.Width, im.Height
+}
+
+// isSmartFill reports whether the image is cropped by the smart gravity.
+func isSmartFill(im *Image) bool {
+	return im.Mode == ModeFill && im.Width > 0 && im.Height > 0 &&
+		im.FocalPoint == nil && im.Gravity == GravitySmart
+}
+
+// shrinkToSource reduces the box with preserved aspect ratio so that it
+// does not exceed the source image, a zero dimension stays derived.
+func shrinkToSource(img image.Image, width, height int) (int, int) {
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+	factor := 1.0
+	if width > 0 {
+		factor = math.Min(factor, float64(srcW)/float64(width))
+	}
+	if height > 0 {
+		factor = math.Min(factor, float64(srcH)/float64(height))
+	}
+	if factor == 1.0 {
+		return width, height
+	}
+	return scaleSize(width, factor), scaleSize(height, factor)
+}
+
+func scaleSize(size int, factor float64) int {
+	if size == 0 {
+		return 0
+	}
+	return max(1, int(math.Round(float64(size)*factor)))
+}
+
+// anchorPoint returns the top left corner of the area of the size placed in
+// the bounds by the gravity, keeping the margin from the edges.
+func anchorPoint(bounds image.Rectangle, size image.Point, gravity string, margin int) image.Point {
+	x := (bounds.Dx() - size.X) / 2
+	y := (bounds.Dy() - size.Y) / 2
+	switch gravity {
+	case GravityLeft, GravityTopLeft, GravityBottomLeft:
+		x = margin
+	case GravityRight, GravityTopRight, GravityBottomRight:
+		x = bounds.Dx() - size.X - margin
+	}
+	switch gravity {
+	case GravityTop, GravityTopLeft, GravityTopRight:
+		y = margin
+	case GravityBottom, GravityBottomLeft, GravityBottomRight:
+		y = bounds.Dy() - size.Y - margin
+	}
+	return bounds.Min.Add(image.Pt(x, y))
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// smartFill scales the image to cover the box and moves the crop window along
+// the overflowing side to the region with the highest edge density.
+func smartFill(img image.Image, width, height int, filter imaging.ResampleFilter) *image.NRGBA {
+	cover := coverResize(img, width, height, filter)
+	x, y := smartOffset(cover, width, height)
+	return imaging.Crop(cover, image.Rect(x, y, x+width, y+height))
+}
+
+// smartFocalPoint returns the center of the smart crop window as the focal
+// point, so the same window can be cropped from the images of the same size.
+func smartFocalPoint(img image.Image, width, height int, filter imaging.ResampleFilter) *FocalPoint {
+	cover := coverResize(img, width, height, filter)
+	x, y := smartOffset(cover, width, height)
+	return &FocalPoint{
+		X: float64(x+width/2) / float64(cover.Bounds().Dx()),
+		Y: float64(y+height/2) / float64(cover.Bounds().Dy()),
+	}
+}
+
+// coverResize scales the image with preserved aspect ratio so that it
+// covers the width x height box completely.
+func coverResize(img image.Image, width, height int, filter imaging.ResampleFilter) *image.NRGBA {
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+	scale := math.Max(float64(width)/float64(srcW), float64(height)/float64(srcH))
+	coverW := int(math.Max(float64(width), math.Round(float64(srcW)*scale)))
+	coverH := int(math.Max(float64(height), math.Round(float64(srcH)*scale)))
+	return imaging.Resize(img, coverW, coverH, filter)
+}
+
+func smartOffset(img *image.NRGBA, width, height int) (x, y int) {
+	energy := edgeEnergy(img)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w > width {
+		columns := make([]float64, w)
+		for i, e := range energy {
+			columns[i%w] += e
+		}
+		return bestWindow(columns, width), 0
+	}
+	if h > height {
+		rows := make([]float64, h)
+		for i, e := range energy {
+			rows[i/w] += e
+		}
+		return 0, bestWindow(rows, height)
+	}
+	return 0, 0
+}
+
+// edgeEnergy returns the gradient magnitude of the luminance for every pixel.
+func edgeEnergy(img *image.NRGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	lum := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*img.Stride + x*4
+			r, g, b := float64(img.Pix[i]), float64(img.Pix[i+1]), float64(img.Pix[i+2])
+			lum[y*w+x] = 0.299*r + 0.587*g + 0.114*b
+		}
+	}
+
+	energy := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy float64
+			if x+1 < w {
+				dx = lum[y*w+x+1] - lum[y*w+x]
+			}
+			if y+1 < h {
+				dy = lum[(y+1)*w+x] - lum[y*w+x]
+			}
+			energy[y*w+x] = math.Abs(dx) + math.Abs(dy)
+		}
+	}
+	return energy
+}
+
+// bestWindow finds the offset of the window with the maximum sum of values,
+// the window closest to the center wins among equal ones.
+func bestWindow(values []float64, size int) int {
+	var sum float64
+	for _, v := range values[:size] {
+		sum += v
+	}
+
+	center := (len(values) - size) / 2
+	best, bestSum := 0, sum
+	for offset := 1; offset <= len(values)-size; offset++ {
+		sum += values[offset+size-1] - values[offset-1]
+		closer := abs(offset-center) < abs(best-center)
+		if sum > bestSum || (sum == bestSum && closer) {
+			best, bestSum = offset, sum
+		}
+	}
+	return best
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// focalFill scales the image to cover the box and centers the crop window on
+// the focal point as far as the image borders allow.
+func focalFill(img image.Image, width, height int, fp *FocalPoint, filter imaging.ResampleFilter) *image.NRGBA {
+	cover := coverResize(img, width, height, filter)
+	w, h := cover.Bounds().Dx(), cover.Bounds().Dy()
+	x := clamp(int(math.Round(fp.X*float64(w)))-width/2, 0, w-width)
+	y := clamp(int(math.Round(fp.Y*float64(h)))-height/2, 0, h-height)
+	return imaging.Crop(cover, image.Rect(x, y, x+width, y+height))
+}
+
+func clamp(v, low, high int) int {
+	return max(low, min(v, high))
+}
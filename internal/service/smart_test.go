@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmartFill(t *testing.T) {
+	for _, test := range []struct {
+		width  int
+		height int
+		detail image.Rectangle
+	}{
+		{width: 120, height: 60, detail: image.Rect(8, 20, 24, 36)},
+		{width: 120, height: 60, detail: image.Rect(96, 20, 112, 36)},
+		{width: 60, height: 120, detail: image.Rect(20, 8, 36, 24)},
+		{width: 60, height: 120, detail: image.Rect(20, 96, 36, 112)},
+	} {
+		t.Run(fmt.Sprintf("smart fill %dx%d detail %v", test.width, test.height, test.detail), func(t *testing.T) {
+			img := detailImage(test.width, test.height, test.detail)
+
+			smart := smartFill(img, 60, 60, imaging.Lanczos)
+			require.Equal(t, 60, smart.Bounds().Dx())
+			require.Equal(t, 60, smart.Bounds().Dy())
+
+			center := imaging.Fill(img, 60, 60, imaging.Center, imaging.Lanczos)
+			require.Greater(t, sum(edgeEnergy(smart)), sum(edgeEnergy(center)))
+		})
+	}
+}
+
+// detailImage returns a white image with the checkerboard in the rectangle.
+func detailImage(width, height int, detail image.Rectangle) *image.NRGBA {
+	img := imaging.New(width, height, color.White)
+	for y := detail.Min.Y; y < detail.Max.Y; y++ {
+		for x := detail.Min.X; x < detail.Max.X; x++ {
+			if (x/2+y/2)%2 == 0 {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	return img
+}
+
+func TestBestWindow(t *testing.T) {
+	t.Run("window with the highest sum", func(t *testing.T) {
+		require.Equal(t, 4, bestWindow([]float64{1, 0, 0, 0, 5, 5, 0}, 2))
+	})
+
+	t.Run("window closest to the center for equal sums", func(t *testing.T) {
+		require.Equal(t, 2, bestWindow([]float64{1, 1, 1, 1, 1, 1}, 2))
+	})
+}
+
+func sum(values []float64) (total float64) {
+	for _, v := range values {
+		total += v
+	}
+	return
+}
+
+func TestFocalFill(t *testing.T) {
+	img := testImage(100, 60)
+	cover := coverResize(img, 30, 30, imaging.Lanczos)
+
+	for _, test := range []struct {
+		fp     FocalPoint
+		anchor imaging.Anchor
+	}{
+		{fp: FocalPoint{X: 0, Y: 0.5}, anchor: imaging.Left},
+		{fp: FocalPoint{X: 0.5, Y: 0.5}, anchor: imaging.Center},
+		{fp: FocalPoint{X: 1, Y: 0.5}, anchor: imaging.Right},
+	} {
+		t.Run(fmt.Sprintf("focal fill %v", test.fp), func(t *testing.T) {
+			focal := focalFill(img, 30, 30, &test.fp, imaging.Lanczos)
+			require.Equal(t, imaging.CropAnchor(cover, 30, 30, test.anchor).Pix, focal.Pix)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+const FormatSVG = "svg"
+
+// rasterizeSVG renders the vector image at the size it gets after resizing,
+// so the resize step does not scale the bitmap.
+func rasterizeSVG(path string, im *Image) (*image.NRGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	icon, err := oksvg.ReadIconStream(file, oksvg.IgnoreErrorMode)
+	if err != nil {
+		return nil, err
+	}
+	if !(icon.ViewBox.W > 0 && icon.ViewBox.H > 0) {
+		return nil, fmt.Errorf("svg has empty view box")
+	}
+
+	scale := svgScale(icon.ViewBox.W, icon.ViewBox.H, im)
+	width := min(max(1, int(math.Round(icon.ViewBox.W*scale))), maxSize)
+	height := min(max(1, int(math.Round(icon.ViewBox.H*scale))), maxSize)
+	icon.Transform = rasterx.Identity.
+		Scale(float64(width)/icon.ViewBox.W, float64(height)/icon.ViewBox.H).
+		Translate(-icon.ViewBox.X, -icon.ViewBox.Y)
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	scanner := rasterx.NewScannerGV(width, height, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(width, height, scanner), 1)
+	return img, nil
+}
+
+// svgScale returns the scale of the view box covering the requested size in
+// fill mode and fitting into it otherwise, the operations use the view box size.
+func svgScale(w, h float64, im *Image) float64 {
+	if im.Rotate == 90 || im.Rotate == 270 {
+		w, h = h, w
+	}
+	scaleW, scaleH := float64(im.Width)/w, float64(im.Height)/h
+	switch {
+	case im.Mode == ModeOps:
+		return 1
+	case im.Width == 0:
+		return scaleH
+	case im.Height == 0:
+		return scaleW
+	case im.Mode == ModeFill:
+		return math.Max(scaleW, scaleH)
+	default:
+		return math.Min(scaleW, scaleH)
+	}
+}
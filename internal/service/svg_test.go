@@ -0,0 +1,64 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AndreiGoStorm/previewer/internal/logger"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRasterizeSVG(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	for _, test := range []struct {
+		name   string
+		im     Image
+		bounds image.Rectangle
+	}{
+		{
+			name:   "fill covers the size",
+			im:     Image{Mode: ModeFill, Width: 100, Height: 100},
+			bounds: image.Rect(0, 0, 200, 100),
+		},
+		{name: "fit into the size", im: Image{Mode: ModeFit, Width: 100, Height: 100}, bounds: image.Rect(0, 0, 100, 50)},
+		{name: "auto height", im: Image{Mode: ModeFill, Width: 400}, bounds: image.Rect(0, 0, 400, 200)},
+		{name: "rotated", im: Image{Mode: ModeFill, Width: 50, Height: 300, Rotate: 90}, bounds: image.Rect(0, 0, 300, 150)},
+		{name: "operations", im: Image{Mode: ModeOps}, bounds: image.Rect(0, 0, 200, 100)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			img, err := rasterizeSVG("images/image.svg", &test.im)
+			require.NoError(t, err)
+			require.Equal(t, test.bounds, img.Bounds())
+			require.Equal(t, red, img.NRGBAAt(test.bounds.Dx()/4, test.bounds.Dy()/2))
+			require.Equal(t, uint8(0), img.NRGBAAt(test.bounds.Dx()-1, 0).A)
+		})
+	}
+}
+
+func TestPreviewerResizeSVG(t *testing.T) {
+	previewer := New(logger.New("INFO"))
+	defer os.RemoveAll(previewer.Storage.Dir)
+
+	im := &Image{
+		Mode:            ModeFill,
+		Width:           64,
+		Height:          64,
+		Gravity:         GravityLeft,
+		Ext:             ".svg",
+		ImageName:       "image_from_svg.png",
+		LoadedImageName: "image_for_rasterize.svg",
+	}
+	copyTestImage(t, "images/image.svg", filepath.Join(previewer.Storage.Dir, im.LoadedImageName))
+
+	err := previewer.Resize(im)
+	require.NoError(t, err)
+
+	img, err := imaging.Open(filepath.Join(previewer.Storage.Dir, im.ImageName))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+	require.Equal(t, color.NRGBA{R: 255, A: 255}, color.NRGBAModel.Convert(img.At(32, 32)))
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+// trim crops away the border of the color of the top left pixel, pixels which
+// channels differ from it by no more than the tolerance belong to the border.
+// The uniform image is returned as is.
+func trim(img image.Image, tolerance int) *image.NRGBA {
+	src := imaging.Clone(img)
+	return imaging.Crop(src, trimRect(src, tolerance))
+}
+
+// trimRect returns the rectangle of the image inside the uniform border, it is
+// the whole image when the image is uniform.
+func trimRect(src *image.NRGBA, tolerance int) image.Rectangle {
+	border := src.NRGBAAt(src.Bounds().Min.X, src.Bounds().Min.Y)
+	inBorder := func(x0, y0, x1, y1 int) bool {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				if !similarColor(src.NRGBAAt(x, y), border, tolerance) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	rect := src.Bounds()
+	for rect.Min.Y < rect.Max.Y && inBorder(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+1) {
+		rect.Min.Y++
+	}
+	if rect.Empty() {
+		return src.Bounds()
+	}
+	for inBorder(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y) {
+		rect.Max.Y--
+	}
+	for inBorder(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Max.Y) {
+		rect.Min.X++
+	}
+	for inBorder(rect.Max.X-1, rect.Min.Y, rect.Max.X, rect.Max.Y) {
+		rect.Max.X--
+	}
+	return rect
+}
+
+func similarColor(c1, c2 color.NRGBA, tolerance int) bool {
+	return abs(int(c1.R)-int(c2.R)) <= tolerance &&
+		abs(int(c1.G)-int(c2.G)) <= tolerance &&
+		abs(int(c1.B)-int(c2.B)) <= tolerance &&
+		abs(int(c1.A)-int(c2.A)) <= tolerance
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrim(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	img := imaging.New(100, 80, white)
+	for y := 20; y < 50; y++ {
+		for x := 10; x < 70; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	// jpeg-like noise in the border.
+	img.SetNRGBA(90, 5, color.NRGBA{R: 250, G: 252, B: 249, A: 255})
+
+	for _, test := range []struct {
+		name      string
+		img       image.Image
+		tolerance int
+		bounds    image.Rectangle
+	}{
+		{name: "with tolerance", img: img, tolerance: 10, bounds: image.Rect(0, 0, 60, 30)},
+		{name: "noise is not trimmed", img: img, tolerance: 0, bounds: image.Rect(0, 0, 81, 45)},
+		{name: "uniform image", img: imaging.New(10, 10, white), tolerance: 0, bounds: image.Rect(0, 0, 10, 10)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.bounds, trim(test.img, test.tolerance).Bounds())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"image"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+var watermarkPositions = map[string]bool{
+	GravityTopLeft:     true,
+	GravityTopRight:    true,
+	GravityBottomLeft:  true,
+	GravityBottomRight: true,
+	GravityCenter:      true,
+}
+
+// Watermark describes the image overlaid on the preview.
+type Watermark struct {
+	Path     string
+	Position string
+	Opacity  float64
+	Margin   int
+}
+
+func ValidWatermarkPosition(position string) bool {
+	return watermarkPositions[position]
+}
+
+// watermarks keeps decoded watermark images, so the file is read only once.
+type watermarks struct {
+	mu     sync.Mutex
+	images map[string]image.Image
+}
+
+func (wms *watermarks) get(path string) (image.Image, error) {
+	wms.mu.Lock()
+	defer wms.mu.Unlock()
+
+	if img, ok := wms.images[path]; ok {
+		return img, nil
+	}
+	img, err := imaging.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if wms.images == nil {
+		wms.images = make(map[string]image.Image)
+	}
+	wms.images[path] = img
+	return img, nil
+}
+
+// overlayWatermark draws the watermark in the position with the margin from the
+// edges, the watermark is scaled down when it does not fit into the image.
+func overlayWatermark(img, mark image.Image, wm *Watermark) *image.NRGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx()-2*wm.Margin, bounds.Dy()-2*wm.Margin
+	if w <= 0 || h <= 0 {
+		return imaging.Clone(img)
+	}
+	if mark.Bounds().Dx() > w || mark.Bounds().Dy() > h {
+		mark = imaging.Fit(mark, w, h, imaging.Lanczos)
+	}
+
+	position := anchorPoint(bounds, mark.Bounds().Size(), wm.Position, wm.Margin)
+	return imaging.Overlay(img, mark, position, wm.Opacity)
+}
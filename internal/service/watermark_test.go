@@ -0,0 +1,66 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayWatermark(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.NRGBA{A: 255}
+	img := imaging.New(100, 50, black)
+	mark := imaging.New(20, 10, white)
+
+	for _, test := range []struct {
+		position string
+		inside   image.Point
+		outside  image.Point
+	}{
+		{position: GravityTopLeft, inside: image.Pt(5, 5), outside: image.Pt(4, 4)},
+		{position: GravityTopRight, inside: image.Pt(94, 5), outside: image.Pt(95, 4)},
+		{position: GravityBottomLeft, inside: image.Pt(5, 44), outside: image.Pt(4, 45)},
+		{position: GravityBottomRight, inside: image.Pt(94, 44), outside: image.Pt(95, 45)},
+		{position: GravityCenter, inside: image.Pt(40, 20), outside: image.Pt(39, 19)},
+	} {
+		t.Run(test.position, func(t *testing.T) {
+			marked := overlayWatermark(img, mark, &Watermark{Position: test.position, Opacity: 1, Margin: 5})
+			require.Equal(t, white, marked.NRGBAAt(test.inside.X, test.inside.Y))
+			require.Equal(t, black, marked.NRGBAAt(test.outside.X, test.outside.Y))
+		})
+	}
+
+	t.Run("opacity", func(t *testing.T) {
+		marked := overlayWatermark(img, mark, &Watermark{Position: GravityTopLeft, Opacity: 0.5})
+		require.InDelta(t, 128, int(marked.NRGBAAt(0, 0).R), 1)
+	})
+
+	t.Run("scale down large watermark", func(t *testing.T) {
+		large := imaging.New(180, 80, white)
+		marked := overlayWatermark(img, large, &Watermark{Position: GravityTopLeft, Opacity: 1, Margin: 5})
+		require.Equal(t, white, marked.NRGBAAt(5, 5))
+		require.Equal(t, white, marked.NRGBAAt(94, 44))
+		require.Equal(t, black, marked.NRGBAAt(95, 45))
+	})
+}
+
+func TestWatermarksCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watermark.png")
+	require.NoError(t, imaging.Save(imaging.New(4, 2, color.White), path))
+
+	var wms watermarks
+	first, err := wms.get(path)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 4, 2), first.Bounds())
+
+	second, err := wms.get(path)
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	_, err = wms.get(filepath.Join(t.TempDir(), "missing.png"))
+	require.Error(t, err)
+}